@@ -0,0 +1,166 @@
+// gaokao-import 将官方录取数据表格（xlsx/csv）导入gaokao2025表
+//
+//	gaokao-import -file 湖北2024本科批.xlsx -province 湖北 -report errors.csv
+//
+// 表格中有与已有数据重复的专业（生源地、院校代码、专业组代码、专业代码都相同）时拒绝导入；
+// 使用 -replace 先删除表格中各生源地的全部已有数据再导入，用于重新导入或某批插入失败后重试。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/importer"
+	"gaokao-zhiyuan/taxonomy"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "录取数据表格路径（.xlsx/.csv）")
+	sheet := flag.String("sheet", "", "xlsx工作表名称，默认第一个工作表")
+	province := flag.String("province", "", "表格中没有生源地列时使用的省份")
+	batchSize := flag.Int("batch-size", 5000, "每批插入的行数")
+	reportPath := flag.String("report", "", "校验错误报告输出路径（CSV），默认输出到标准错误")
+	skipInvalid := flag.Bool("skip-invalid", false, "跳过校验失败的行继续导入，默认有错误时不导入")
+	dryRun := flag.Bool("dry-run", false, "只校验不导入")
+	replace := flag.Bool("replace", false, "先删除表格中各生源地的全部已有数据再导入，默认与已有数据重复时不导入")
+	taxonomyPath := flag.String("taxonomy", "", "专业分类体系文件，默认使用 TAXONOMY_PATH，未配置时为 hubei_data/major_taxonomy.json")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *batchSize < 1 {
+		log.Fatalf("batch-size必须大于0")
+	}
+
+	// 加载.env文件
+	if err := godotenv.Load(); err != nil {
+		log.Printf("警告: 未找到.env文件或加载失败: %v", err)
+	}
+	cfg := config.LoadConfig()
+
+	records, err := importer.ReadFile(*file, *sheet)
+	if err != nil {
+		log.Fatalf("读取 %s 失败: %v", *file, err)
+	}
+
+	tax, err := loadTaxonomy(*taxonomyPath, cfg)
+	if err != nil {
+		log.Fatalf("加载专业分类体系失败: %v", err)
+	}
+	log.Printf("专业分类体系版本: %s", tax.Version)
+
+	var db *database.ClickHouseDB
+	opts := importer.Options{Province: *province, Taxonomy: tax}
+	if !*dryRun {
+		db, err = database.NewClickHouseDB(cfg, nil)
+		if err != nil {
+			log.Fatalf("连接ClickHouse失败: %v", err)
+		}
+		defer db.Close()
+
+		if _, err := db.Migrate(); err != nil {
+			log.Fatalf("执行表结构迁移失败: %v", err)
+		}
+		if opts.StartID, err = db.MaxAdmissionID(); err != nil {
+			log.Fatalf("查询最大记录ID失败: %v", err)
+		}
+	}
+
+	result, err := importer.Parse(records, opts)
+	if err != nil {
+		log.Fatalf("解析 %s 失败: %v", *file, err)
+	}
+	for _, column := range result.UnknownColumns {
+		log.Printf("忽略无法识别的列: %s", column)
+	}
+	log.Printf("共 %d 行，校验通过 %d 行，错误 %d 个", result.Total, len(result.Rows), len(result.Errors))
+
+	if len(result.Errors) > 0 {
+		if err := writeReport(*reportPath, result.Errors); err != nil {
+			log.Fatalf("输出校验报告失败: %v", err)
+		}
+		if !*skipInvalid {
+			log.Fatalf("存在校验错误，未导入任何数据（使用 -skip-invalid 跳过错误行）")
+		}
+	}
+	if *dryRun {
+		return
+	}
+
+	provinces := importer.Provinces(result.Rows)
+	if *replace {
+		if err := db.DeleteAdmissions(provinces); err != nil {
+			log.Fatalf("删除已有数据失败: %v", err)
+		}
+		log.Printf("已删除 %s 的已有数据", strings.Join(provinces, "、"))
+	} else {
+		existing, err := db.ListAdmissionKeys(provinces)
+		if err != nil {
+			log.Fatalf("查询已有数据失败: %v", err)
+		}
+		if conflicts := importer.Conflicts(result.Rows, existing); len(conflicts) > 0 {
+			row := conflicts[0]
+			log.Fatalf("有 %d 行与已有数据重复（如 %s %s 专业组%s 专业%s），未导入任何数据（使用 -replace 覆盖导入）",
+				len(conflicts), row.SourceProvince, row.SchoolCode, row.MajorGroupCode, row.MajorCode)
+		}
+	}
+
+	for start := 0; start < len(result.Rows); start += *batchSize {
+		end := start + *batchSize
+		if end > len(result.Rows) {
+			end = len(result.Rows)
+		}
+		if err := db.InsertAdmissions(result.Rows[start:end]); err != nil {
+			log.Fatalf("插入第 %d-%d 行失败: %v", start+1, end, err)
+		}
+		log.Printf("已插入 %d/%d 行", end, len(result.Rows))
+	}
+	log.Printf("导入完成")
+}
+
+// 输出校验报告，未指定路径时输出到标准错误
+func writeReport(path string, errs []importer.RowError) error {
+	if path == "" {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e.Error())
+		}
+		return nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := importer.WriteReport(file, errs); err != nil {
+		return err
+	}
+	log.Printf("校验报告已写入 %s", path)
+	return nil
+}
+
+// 加载专业分类体系，-taxonomy 优先于 TAXONOMY_PATH
+func loadTaxonomy(path string, cfg *config.Config) (*taxonomy.Taxonomy, error) {
+	if path == "" {
+		path = cfg.TaxonomyPath
+	}
+	if path == "" {
+		path = "hubei_data/major_taxonomy.json"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return taxonomy.Parse(file)
+}
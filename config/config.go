@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -13,6 +14,32 @@ type Config struct {
 	ClickHouseUser     string
 	ClickHousePassword string
 	ClickHouseDatabase string
+	// 存储后端: clickhouse 或 memory
+	StoreBackend string
+	// memory 后端使用的gaokao2025数据快照（JSON/CSV）
+	SnapshotPath string
+	// memory 后端可选的admission_history历年录取数据快照（JSON/CSV）
+	HistorySnapshotPath string
+	// 一分一段表目录，为空时使用编译时嵌入的 hubei_data 数据
+	ScoreRankDir string
+	// 启动时必须存在的一分一段表，格式为 省份:年份，多个用逗号分隔
+	ScoreRankRequired []string
+	// 启动时是否自动执行未执行的表结构迁移，关闭时只校验表结构
+	MigrateOnStart bool
+	// 冲稳保分档的录取概率下限，格式为 冲,稳,保
+	ProbabilityBands string
+	// 录取线年际波动的标准差（分），用于估算录取概率
+	ProbabilitySigmaPoints float64
+	// 位次法的默认窗口，格式为 冲下限,稳下限,保下限,保上限（带%为考生位次的百分比）
+	RankWindows string
+	// 线差法的默认窗口，格式为 冲下限,稳下限,保下限,保上限（考生线差减专业组线差）
+	LineDiffWindows string
+	// 控制线数据文件，为空时使用编译时嵌入的 hubei_data/control_lines.json
+	ControlLinePath string
+	// 生成志愿表时冲稳保各档志愿数的默认比例，格式为 冲:稳:保
+	PlanTierRatio string
+	// 专业分类体系文件，为空时使用编译时嵌入的 hubei_data/major_taxonomy.json
+	TaxonomyPath string
 }
 
 func LoadConfig() *Config {
@@ -20,15 +47,32 @@ func LoadConfig() *Config {
 	ginMode := getEnv("GIN_MODE", "release")
 
 	clickhousePort, _ := strconv.Atoi(getEnv("CLICKHOUSE_PORT", "19000"))
+	sigmaPoints, err := strconv.ParseFloat(getEnv("PROBABILITY_SIGMA_POINTS", "5"), 64)
+	if err != nil || sigmaPoints <= 0 {
+		sigmaPoints = 5
+	}
 
 	return &Config{
-		Port:               port,
-		GinMode:            ginMode,
-		ClickHouseHost:     getEnv("CLICKHOUSE_HOST", "localhost"),
-		ClickHousePort:     clickhousePort,
-		ClickHouseUser:     getEnv("CLICKHOUSE_USERNAME", "default"),
-		ClickHousePassword: getEnv("CLICKHOUSE_PASSWORD", ""),
-		ClickHouseDatabase: getEnv("CLICKHOUSE_DATABASE", "gaokao"),
+		Port:                   port,
+		GinMode:                ginMode,
+		ClickHouseHost:         getEnv("CLICKHOUSE_HOST", "localhost"),
+		ClickHousePort:         clickhousePort,
+		ClickHouseUser:         getEnv("CLICKHOUSE_USERNAME", "default"),
+		ClickHousePassword:     getEnv("CLICKHOUSE_PASSWORD", ""),
+		ClickHouseDatabase:     getEnv("CLICKHOUSE_DATABASE", "gaokao"),
+		StoreBackend:           getEnv("STORE_BACKEND", "clickhouse"),
+		SnapshotPath:           getEnv("SNAPSHOT_PATH", "hubei_data/gaokao2025_snapshot.json"),
+		HistorySnapshotPath:    getEnv("HISTORY_SNAPSHOT_PATH", ""),
+		ScoreRankDir:           getEnv("SCORE_RANK_DIR", ""),
+		ScoreRankRequired:      splitList(getEnv("SCORE_RANK_REQUIRED", "湖北:2024")),
+		MigrateOnStart:         getEnv("MIGRATE_ON_START", "true") == "true",
+		ProbabilityBands:       getEnv("PROBABILITY_BANDS", "0.15,0.5,0.85"),
+		ProbabilitySigmaPoints: sigmaPoints,
+		RankWindows:            getEnv("RANK_WINDOWS", "-20%,0%,15%,50%"),
+		LineDiffWindows:        getEnv("LINE_DIFF_WINDOWS", "-20,-3,5,20"),
+		ControlLinePath:        getEnv("CONTROL_LINE_PATH", ""),
+		PlanTierRatio:          getEnv("PLAN_TIER_RATIO", "3:4:3"),
+		TaxonomyPath:           getEnv("TAXONOMY_PATH", ""),
 	}
 }
 
@@ -38,3 +82,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// 解析逗号分隔的配置项，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,101 @@
+package config
+
+// 默认生源省份
+const DefaultProvince = "湖北"
+
+// ProvinceProfile 生源省份的招生配置
+type ProvinceProfile struct {
+	Name              string   `json:"name"`               // 省份名称，与 source_province 字段取值一致
+	Code              string   `json:"code"`               // 省份拼音代码，用于数据文件命名
+	SubjectCategories []string `json:"subject_categories"` // 科类（首选科目），物理/历史 或 综合
+	DefaultCategory   string   `json:"default_category"`   // 默认科类
+	Batches           []string `json:"batches"`            // 录取批次
+	DefaultBatch      string   `json:"default_batch"`      // 默认批次
+	// 各批次可填报的院校专业组志愿数，没有配置的批次生成志愿表时需要指定志愿数
+	VolunteerLimits map[string]int `json:"volunteer_limits,omitempty"`
+}
+
+// 支持的生源省份
+var provinceProfiles = []ProvinceProfile{
+	{Name: "湖北", Code: "hubei", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批", VolunteerLimits: map[string]int{"本科批": 45, "专科批": 45}},
+	{Name: "湖南", Code: "hunan", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "广东", Code: "guangdong", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "江苏", Code: "jiangsu", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "河北", Code: "hebei", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "福建", Code: "fujian", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "辽宁", Code: "liaoning", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "重庆", Code: "chongqing", SubjectCategories: []string{"物理", "历史"}, DefaultCategory: "物理", Batches: []string{"本科批", "专科批"}, DefaultBatch: "本科批"},
+	{Name: "山东", Code: "shandong", SubjectCategories: []string{"综合"}, DefaultCategory: "综合", Batches: []string{"普通类一段", "普通类二段"}, DefaultBatch: "普通类一段"},
+	{Name: "浙江", Code: "zhejiang", SubjectCategories: []string{"综合"}, DefaultCategory: "综合", Batches: []string{"普通类一段", "普通类二段"}, DefaultBatch: "普通类一段"},
+	{Name: "北京", Code: "beijing", SubjectCategories: []string{"综合"}, DefaultCategory: "综合", Batches: []string{"本科普通批", "专科普通批"}, DefaultBatch: "本科普通批"},
+}
+
+// GetProvinceProfile 根据省份名称获取招生配置，名称为空时返回默认省份
+func GetProvinceProfile(name string) (*ProvinceProfile, bool) {
+	if name == "" {
+		name = DefaultProvince
+	}
+	for i := range provinceProfiles {
+		if provinceProfiles[i].Name == name {
+			return &provinceProfiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetProvinceProfileByCode 根据省份拼音代码获取招生配置
+func GetProvinceProfileByCode(code string) (*ProvinceProfile, bool) {
+	for i := range provinceProfiles {
+		if provinceProfiles[i].Code == code {
+			return &provinceProfiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// ProvinceProfiles 返回所有支持的省份
+func ProvinceProfiles() []ProvinceProfile {
+	return provinceProfiles
+}
+
+// HasCategory 判断科类是否属于该省份
+func (p *ProvinceProfile) HasCategory(category string) bool {
+	for _, c := range p.SubjectCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBatch 判断批次是否属于该省份
+func (p *ProvinceProfile) HasBatch(batch string) bool {
+	for _, b := range p.Batches {
+		if b == batch {
+			return true
+		}
+	}
+	return false
+}
+
+// VolunteerLimit 批次可填报的志愿数，没有配置时返回false
+func (p *ProvinceProfile) VolunteerLimit(batch string) (int, bool) {
+	limit, ok := p.VolunteerLimits[batch]
+	return limit, ok
+}
+
+// ResolveCategory 返回有效的科类，为空时使用默认科类
+func (p *ProvinceProfile) ResolveCategory(category string) string {
+	if category == "" {
+		return p.DefaultCategory
+	}
+	return category
+}
+
+// ResolveBatch 返回有效的批次，为空时使用默认批次
+func (p *ProvinceProfile) ResolveBatch(batch string) string {
+	if batch == "" {
+		return p.DefaultBatch
+	}
+	return batch
+}
@@ -0,0 +1,122 @@
+// Package controlline 管理各省份的批次线和特殊类型招生控制线（特控线）
+package controlline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gaokao-zhiyuan/config"
+)
+
+// 控制线类型
+const (
+	TypeBatch   = "批次线"
+	TypeSpecial = "特控线" // 特殊类型招生控制线
+)
+
+// Line 某省份某年某科类某批次的控制线
+type Line struct {
+	Province string `json:"province"`
+	Year     int    `json:"year"`
+	Category string `json:"subject_category"`
+	Batch    string `json:"batch"`
+	Type     string `json:"type"`
+	Score    int    `json:"score"`
+}
+
+// Set 控制线数据集，按 省份/年份/科类/批次/类型 索引
+type Set struct {
+	lines map[string]Line
+}
+
+func lineKey(province string, year int, category, batch, lineType string) string {
+	return fmt.Sprintf("%s|%d|%s|%s|%s", province, year, category, batch, lineType)
+}
+
+// Parse 解析控制线JSON：{"data": [{"province": "湖北", "year": 2024, "subject_category": "物理", "batch": "本科批", "type": "批次线", "score": 437}, ...]}，
+// 省份、科类、批次必须属于省份配置，重复的控制线返回错误
+func Parse(r io.Reader) (*Set, error) {
+	var doc struct {
+		Data []Line `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	s := &Set{lines: make(map[string]Line)}
+	for i, line := range doc.Data {
+		if err := validate(line); err != nil {
+			return nil, fmt.Errorf("第%d条控制线: %v", i+1, err)
+		}
+		key := lineKey(line.Province, line.Year, line.Category, line.Batch, line.Type)
+		if _, ok := s.lines[key]; ok {
+			return nil, fmt.Errorf("第%d条控制线重复: %d年%s%s类%s%s", i+1, line.Year, line.Province, line.Category, line.Batch, line.Type)
+		}
+		s.lines[key] = line
+	}
+	return s, nil
+}
+
+func validate(line Line) error {
+	profile, ok := config.GetProvinceProfile(line.Province)
+	if !ok || line.Province == "" {
+		return fmt.Errorf("不支持的省份: %s", line.Province)
+	}
+	if !profile.HasCategory(line.Category) {
+		return fmt.Errorf("%s不支持科类: %s", profile.Name, line.Category)
+	}
+	if !profile.HasBatch(line.Batch) {
+		return fmt.Errorf("%s不支持批次: %s", profile.Name, line.Batch)
+	}
+	if line.Type != TypeBatch && line.Type != TypeSpecial {
+		return fmt.Errorf("未知的控制线类型: %s", line.Type)
+	}
+	if line.Year < 2000 || line.Year > 2100 {
+		return fmt.Errorf("年份错误: %d", line.Year)
+	}
+	if line.Score <= 0 || line.Score > 750 {
+		return fmt.Errorf("分数超出范围: %d", line.Score)
+	}
+	return nil
+}
+
+// Get 查询控制线
+func (s *Set) Get(province string, year int, category, batch, lineType string) (Line, bool) {
+	line, ok := s.lines[lineKey(province, year, category, batch, lineType)]
+	return line, ok
+}
+
+// LineDiff 计算线差（分数减控制线），没有对应控制线时返回错误
+func (s *Set) LineDiff(province string, year int, category, batch, lineType string, score int) (int, error) {
+	line, ok := s.Get(province, year, category, batch, lineType)
+	if !ok {
+		return 0, fmt.Errorf("没有%d年%s%s类%s%s", year, province, category, batch, lineType)
+	}
+	return score - line.Score, nil
+}
+
+// Lines 返回省份的控制线，year 为0时返回所有年份，按年份降序、科类、批次、类型排序
+func (s *Set) Lines(province string, year int) []Line {
+	var lines []Line
+	for _, line := range s.lines {
+		if line.Province == province && (year == 0 || line.Year == year) {
+			lines = append(lines, line)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if a.Year != b.Year {
+			return a.Year > b.Year
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Batch != b.Batch {
+			return a.Batch < b.Batch
+		}
+		return a.Type < b.Type
+	})
+	return lines
+}
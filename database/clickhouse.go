@@ -3,24 +3,26 @@ package database
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 
 	"gaokao-zhiyuan/config"
 	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/querybuilder"
+	"gaokao-zhiyuan/scorerank"
+	"gaokao-zhiyuan/taxonomy"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
 type ClickHouseDB struct {
-	conn driver.Conn
+	conn  driver.Conn
+	ranks *scorerank.Registry
 }
 
-func NewClickHouseDB(cfg *config.Config) (*ClickHouseDB, error) {
+func NewClickHouseDB(cfg *config.Config, ranks *scorerank.Registry) (*ClickHouseDB, error) {
 	// 先尝试连接到指定数据库
 	conn, err := clickhouse.Open(&clickhouse.Options{
 		Addr: []string{fmt.Sprintf("%s:%d", cfg.ClickHouseHost, cfg.ClickHousePort)},
@@ -51,7 +53,7 @@ func NewClickHouseDB(cfg *config.Config) (*ClickHouseDB, error) {
 		}
 
 		// 创建目标数据库
-		if err := defaultConn.Exec(context.Background(), fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", cfg.ClickHouseDatabase)); err != nil {
+		if err := defaultConn.Exec(context.Background(), "CREATE DATABASE IF NOT EXISTS "+querybuilder.Ident(cfg.ClickHouseDatabase)); err != nil {
 			defaultConn.Close()
 			return nil, fmt.Errorf("创建数据库失败: %v", err)
 		}
@@ -75,61 +77,70 @@ func NewClickHouseDB(cfg *config.Config) (*ClickHouseDB, error) {
 		}
 	}
 
-	return &ClickHouseDB{conn: conn}, nil
+	return &ClickHouseDB{conn: conn, ranks: ranks}, nil
 }
 
 func (db *ClickHouseDB) Close() error {
 	return db.conn.Close()
 }
 
-// 创建新的湖北省数据表
-func (db *ClickHouseDB) CreateTable() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS gaokao2025 (
-		id                      UInt32,
-		school_code             String,
-		school_name             String,
-		major_code              String,
-		major_name              String,
-		major_group_code        String,
-		source_province         Enum8('湖北' = 1),
-		school_province         String,
-		school_city             String,
-		admission_batch         Enum8('本科批' = 1, '专科批' = 2),
-		subject_category        Enum8('物理' = 1, '历史' = 2),
-		require_physics         Bool,
-		require_chemistry       Bool,
-		require_biology         Bool,
-		require_politics        Bool,
-		require_history         Bool,
-		require_geography       Bool,
-		subject_requirement_raw String,
-		school_type             String,
-		school_ownership        Enum8('公办' = 1, '内地与港澳台合作办学' = 2, '中外合作办学' = 3, '民办' = 4, '境外高校独立办学' = 5),
-		school_authority        String,
-		school_level            String,
-		school_tags             String,
-		education_level         Enum8('本科' = 1, '职业本科' = 2, '专科' = 3),
-		major_description       String,
-		study_duration          UInt8,
-		tuition_fee             String,
-		is_new_major            Bool,
-		min_score_2024          UInt16,
-		min_rank_2024           UInt32,
-		major_min_score_2024    UInt16,
-		enrollment_plan_2024    UInt16,
-		is_science              Bool,
-		is_engineering          Bool,
-		is_medical              Bool,
-		is_economics_mgmt_law   Bool,
-		is_liberal_arts         Bool,
-		is_design_arts          Bool,
-		is_language             Bool
-	) ENGINE = MergeTree()
-	ORDER BY (id, school_code, major_code)
-	SETTINGS index_granularity = 8192
-	`
-	return db.conn.Exec(context.Background(), query)
+// 批量插入gaokao2025录取数据
+func (db *ClickHouseDB) InsertAdmissions(rows []models.AdmissionHubeiWide) error {
+	columns := ModelColumns(models.AdmissionHubeiWide{})
+	batch, err := db.conn.PrepareBatch(context.Background(), "INSERT INTO gaokao2025 ("+columnNames(columns)+")")
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		if err := batch.AppendStruct(&rows[i]); err != nil {
+			return err
+		}
+	}
+
+	return batch.Send()
+}
+
+// 查询gaokao2025当前最大的记录ID，用于导入时分配新ID
+func (db *ClickHouseDB) MaxAdmissionID() (uint32, error) {
+	var maxID uint32
+	if err := db.conn.QueryRow(context.Background(), "SELECT max(id) FROM gaokao2025").Scan(&maxID); err != nil {
+		return 0, err
+	}
+	return maxID, nil
+}
+
+// 查询省份已有录取数据的院校、专业组和专业代码，用于导入前检查重复
+func (db *ClickHouseDB) ListAdmissionKeys(provinces []string) ([]models.AdmissionHubeiWide, error) {
+	query, args := admissionKeysQuery(provinces).Build()
+	rows, err := db.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.AdmissionHubeiWide
+	for rows.Next() {
+		var row models.AdmissionHubeiWide
+		if err := rows.Scan(&row.SourceProvince, &row.SchoolCode, &row.MajorGroupCode, &row.MajorCode); err != nil {
+			return nil, err
+		}
+		keys = append(keys, row)
+	}
+	return keys, rows.Err()
+}
+
+func admissionKeysQuery(provinces []string) *querybuilder.SelectBuilder {
+	return querybuilder.Select("DISTINCT source_province", "school_code", "major_group_code", "major_code").
+		From("gaokao2025").
+		Where(querybuilder.In("source_province", provinces))
+}
+
+// 删除省份的全部录取数据，等待删除完成后返回，用于覆盖导入
+func (db *ClickHouseDB) DeleteAdmissions(provinces []string) error {
+	cond := querybuilder.In("source_province", provinces)
+	ctx := clickhouse.Context(context.Background(), clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+	return db.conn.Exec(ctx, "ALTER TABLE gaokao2025 DELETE WHERE "+querybuilder.Number(cond.SQL), cond.Args...)
 }
 
 // 创建旧表（保持兼容性）
@@ -189,551 +200,388 @@ func (db *ClickHouseDB) BatchInsert(data []models.AdmissionData) error {
 	return batch.Send()
 }
 
-// 根据分数查询位次 - 使用新表
-func (db *ClickHouseDB) QueryRankByScoreNew(score float64, subjectCategory string) (int64, error) {
-	// 查询语句：根据分数查询位次
-	query := `
-		SELECT min_rank_2024
-		FROM gaokao2025
-		WHERE min_score_2024 >= $1
-		AND min_rank_2024 > 0
-		AND subject_category = $2
-		ORDER BY min_score_2024 ASC
-		LIMIT 1
-	`
+// 报表查询的数据来源和筛选条件
+type reportFilter struct {
+	from        querybuilder.Cond
+	where       []querybuilder.Cond
+	scoreColumn string
+	rankColumn  string
+}
 
-	var rank uint32
-	err := db.conn.QueryRow(context.Background(), query, score, subjectCategory).Scan(&rank)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// 如果没有找到记录，查询最高分对应的位次
-			estimateQuery := `
-				SELECT min_rank_2024
-				FROM gaokao2025
-				WHERE min_score_2024 > 0
-				AND min_rank_2024 > 0
-				AND subject_category = $1
-				ORDER BY min_score_2024 DESC
-				LIMIT 1
-			`
-			var estimateRank uint32
-			err = db.conn.QueryRow(context.Background(), estimateQuery, subjectCategory).Scan(&estimateRank)
-			if err != nil {
-				return 0, errors.New("无法估算位次")
-			}
-			return int64(estimateRank), nil
-		}
-		return 0, err
+// 按报表查询参数构建筛选条件
+func (db *ClickHouseDB) buildReportFilter(q *models.ReportQuery) *reportFilter {
+	// 参考年份不是宽表年份时，从历年录取数据中关联该年的分数线
+	from, scoreColumn, rankColumn := cutoffSource(q.Year)
+	var conditions []querybuilder.Cond
+
+	// 0. 生源省份、科类和批次
+	conditions = append(conditions,
+		querybuilder.Eq("source_province", q.Province),
+		querybuilder.Eq("subject_category", q.ClassFirstChoice),
+		querybuilder.In("admission_batch", reportBatches(q)))
+
+	// 1. 一次筛选：选科分类
+	conditions = append(conditions, db.buildSubjectConditions(q.ClassFirstChoice, q.ClassOptionalChoice))
+
+	// 2. 二次筛选：院校所在省份
+	if len(q.CollegeLocation) > 0 {
+		conditions = append(conditions, querybuilder.In("school_province", q.CollegeLocation))
 	}
 
-	return int64(rank), nil
-}
+	// 3. 三次筛选：意向专业方向及专业类
+	conditions = append(conditions, db.buildInterestConditions(q.InterestFlags))
+	if len(q.MajorCategories) > 0 {
+		conditions = append(conditions, querybuilder.Expr("has(?, substring(major_code, 1, 4))", q.MajorCategories))
+	}
 
-// 根据分数查询位次
-func (db *ClickHouseDB) QueryRankByScore(province string, year int, score float64, subjectType string, classDemands []string) (int64, error) {
-	// 构建科目类型和选科要求的条件
-	classDemandCondition := ""
-	if len(classDemands) > 0 {
-		conditions := make([]string, 0, len(classDemands))
-		for _, demand := range classDemands {
-			conditions = append(conditions, fmt.Sprintf("subject_requirement_raw LIKE '%%%s%%'", demand))
-		}
-		classDemandCondition = "AND (" + strings.Join(conditions, " OR ") + ")"
-	}
-
-	// 查询语句：根据分数查询位次
-	// 使用min_rank_2024排序，找到分数大于等于给定分数的最大位次
-	query := fmt.Sprintf(`
-		SELECT min_rank_2024
-		FROM default.gaokao2025
-		WHERE source_province = '湖北'
-		AND subject_category = $1
-		%s
-		AND min_score_2024 >= $2
-		ORDER BY min_score_2024 ASC
-		LIMIT 1
-	`, classDemandCondition)
-
-	var rank uint32
-	err := db.conn.QueryRow(context.Background(), query, subjectType, score).Scan(&rank)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// 如果没有找到记录，查询该省份该年份最低分最高的记录的位次
-			estimateQuery := `
-				SELECT min_rank_2024
-				FROM default.gaokao2025
-				WHERE source_province = '湖北'
-				AND subject_category = $1
-				AND min_score_2024 > 0
-				ORDER BY min_score_2024 DESC
-				LIMIT 1
-			`
-			var estimateRank uint32
-			err = db.conn.QueryRow(context.Background(), estimateQuery, subjectType).Scan(&estimateRank)
-			if err != nil {
-				return 0, errors.New("无法估算位次")
-			}
-			return int64(estimateRank), nil
-		}
-		return 0, err
+	// 4. 模糊专业名称筛选
+	if q.FuzzySubjectCategory != "" {
+		conditions = append(conditions, querybuilder.Contains("major_name", q.FuzzySubjectCategory))
+	}
+	if q.MajorCode != "" {
+		conditions = append(conditions, querybuilder.Eq("major_code", q.MajorCode))
 	}
 
-	return int64(rank), nil
-}
+	// 5. 录取位次或录取分筛选 - 冲稳保策略
+	if q.MaxCutoffRank > 0 {
+		conditions = append(conditions, querybuilder.Between(rankColumn, q.MinCutoffRank, q.MaxCutoffRank))
+	}
+	if q.MaxCutoffScore > 0 {
+		conditions = append(conditions, querybuilder.Between(scoreColumn, q.MinCutoffScore, q.MaxCutoffScore))
+	}
 
-// 根据位次查询分数
-func (db *ClickHouseDB) QueryScoreByRank(province string, year int, rank int64, subjectType string, classDemands []string) (int64, error) {
-	// 构建科目类型和选科要求的条件
-	classDemandCondition := ""
-	if len(classDemands) > 0 {
-		conditions := make([]string, 0, len(classDemands))
-		for _, demand := range classDemands {
-			conditions = append(conditions, fmt.Sprintf("subject_requirement_raw LIKE '%%%s%%'", demand))
-		}
-		classDemandCondition = "AND (" + strings.Join(conditions, " OR ") + ")"
-	}
-
-	// 查询语句：根据位次查询分数
-	// 使用min_rank_2024排序，找到位次小于等于给定位次的最低分
-	query := fmt.Sprintf(`
-		SELECT min_score_2024
-		FROM default.gaokao2025
-		WHERE source_province = '湖北'
-		AND subject_category = $1
-		%s
-		AND min_rank_2024 <= $2
-		AND min_rank_2024 > 0
-		ORDER BY min_rank_2024 DESC
-		LIMIT 1
-	`, classDemandCondition)
-
-	var score uint16
-	err := db.conn.QueryRow(context.Background(), query, subjectType, rank).Scan(&score)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// 如果没有找到记录，查询该省份该年份最高位次最低的记录的分数
-			estimateQuery := `
-				SELECT min_score_2024
-				FROM default.gaokao2025
-				WHERE source_province = '湖北'
-				AND subject_category = $1
-				AND min_rank_2024 > 0
-				ORDER BY min_rank_2024 ASC
-				LIMIT 1
-			`
-			var estimateScore uint16
-			err = db.conn.QueryRow(context.Background(), estimateQuery, subjectType).Scan(&estimateScore)
-			if err != nil {
-				return 0, errors.New("无法估算分数")
-			}
-			return int64(estimateScore), nil
-		}
-		return 0, err
+	// 6. 结构化筛选条件
+	conditions = append(conditions, compileFilters(&q.Filters)...)
+
+	return &reportFilter{
+		from:        from,
+		where:       conditions,
+		scoreColumn: scoreColumn,
+		rankColumn:  rankColumn,
 	}
+}
 
-	return int64(score), nil
+// 在筛选条件上构建SELECT语句
+func (f *reportFilter) selectFrom(columns ...string) *querybuilder.SelectBuilder {
+	return querybuilder.Select(columns...).From(f.from.SQL, f.from.Args...).Where(f.where...)
 }
 
-// 新的报表查询接口 - 使用新表结构
-func (db *ClickHouseDB) GetReportDataNew(rank int64, classFirstChoice string, classOptionalChoice []string, province string, page, pageSize int64, collegeLocation []string, interest []string, strategy int, fuzzySubjectCategory string) (*models.Response, error) {
-	log.Printf("报表查询参数: rank=%d, classFirstChoice=%s, classOptionalChoice=%v, province=%s, page=%d, pageSize=%d, collegeLocation=%v, interest=%v, strategy=%d, fuzzySubjectCategory=%s",
-		rank, classFirstChoice, classOptionalChoice, province, page, pageSize, collegeLocation, interest, strategy, fuzzySubjectCategory)
-
-	// 根据位次查询对应分数
-	var rankScoreUint16 uint16
-	scoreQuery := `
-		SELECT min_score_2024 
-		FROM default.gaokao2025 
-		WHERE min_rank_2024 <= ? AND min_rank_2024 > 0 AND subject_category = ?
-		ORDER BY min_rank_2024 DESC 
-		LIMIT 1
-	`
+// 报表行查询的字段，录取分数线字段随参考年份变化
+func (f *reportFilter) selectRows() *querybuilder.SelectBuilder {
+	return f.selectFrom("id", "school_name", "school_code", "major_group_code", "major_code",
+		"subject_requirement_raw", "subject_requirement", "school_province", "school_city",
+		"school_ownership", "school_type", "school_authority", "school_level",
+		"school_tags", "education_level", "major_description", "tuition_fee", "is_new_major",
+		f.scoreColumn, f.rankColumn, "major_name", "study_duration", "major_min_score_2024",
+		"enrollment_plan", "enrollment_plan_2024",
+		"require_physics", "require_chemistry", "require_biology", "require_politics", "require_history", "require_geography",
+		"is_science", "is_engineering", "is_medical", "is_economics_mgmt_law", "is_liberal_arts", "is_design_arts", "is_language")
+}
 
-	row := db.conn.QueryRow(context.Background(), scoreQuery, rank, classFirstChoice)
-	err := row.Scan(&rankScoreUint16)
+// 执行报表行查询，query 由 selectRows 构建
+func (db *ClickHouseDB) queryReportRows(query *querybuilder.SelectBuilder) ([]models.AdmissionHubeiWide, error) {
+	dataQuery, args := query.Build()
+	log.Printf("执行数据查询: %s, args: %v", dataQuery, args)
+	rows, err := db.conn.Query(context.Background(), dataQuery, args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// 如果没有找到精确位次，查询附近的位次
-			nearbyQuery := `
-				SELECT min_score_2024 
-				FROM default.gaokao2025 
-				WHERE min_rank_2024 > 0 AND subject_category = ?
-				ORDER BY ABS(min_rank_2024 - ?)
-				LIMIT 1
-			`
-			row = db.conn.QueryRow(context.Background(), nearbyQuery, classFirstChoice, rank)
-			err = row.Scan(&rankScoreUint16)
-			if err != nil {
-				log.Printf("无法找到位次 %d 附近的数据，使用默认分数 500", rank)
-				rankScoreUint16 = 500 // 默认分数
-			} else {
-				log.Printf("找到位次 %d 附近的数据，对应分数为 %d", rank, rankScoreUint16)
-			}
-		} else {
-			log.Printf("查询位次 %d 对应分数时出错: %v", rank, err)
-			return nil, err
-		}
-	} else {
-		log.Printf("位次 %d 对应的分数为 %d", rank, rankScoreUint16)
+		log.Printf("数据查询失败: %v", err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	// 注意：rankScore 变量在新的排名策略中不再需要，因为我们直接使用用户输入的排名
+	var matched []models.AdmissionHubeiWide
+	for rows.Next() {
+		var row models.AdmissionHubeiWide
+		if err := scanReportRow(rows, &row); err != nil {
+			log.Printf("扫描行数据错误: %v", err)
+			continue
+		}
+		matched = append(matched, row)
+	}
+	return matched, nil
+}
 
-	// 构建查询条件
-	var conditions []string
-	var args []interface{}
-	argIndex := 1
+// 扫描 selectRows 查询的一行
+func scanReportRow(rows driver.Rows, row *models.AdmissionHubeiWide) error {
+	return rows.Scan(&row.ID, &row.SchoolName, &row.SchoolCode, &row.MajorGroupCode, &row.MajorCode, &row.SubjectRequirementRaw, &row.SubjectRequirement,
+		&row.SchoolProvince, &row.SchoolCity, &row.SchoolOwnership, &row.SchoolType, &row.SchoolAuthority,
+		&row.SchoolLevel, &row.SchoolTags, &row.EducationLevel, &row.MajorDescription, &row.TuitionFee, &row.IsNewMajor,
+		&row.MinScore2024, &row.MinRank2024, &row.MajorName, &row.StudyDuration, &row.MajorMinScore2024,
+		&row.EnrollmentPlan, &row.EnrollmentPlan2024,
+		&row.RequirePhysics, &row.RequireChemistry, &row.RequireBiology, &row.RequirePolitics, &row.RequireHistory, &row.RequireGeography,
+		&row.IsScience, &row.IsEngineering, &row.IsMedical, &row.IsEconomicsMgmtLaw, &row.IsLiberalArts, &row.IsDesignArts, &row.IsLanguage)
+}
 
-	// 1. 一次筛选：选科分类
-	subjectConditions := db.buildSubjectConditions(classFirstChoice, classOptionalChoice)
-	if subjectConditions != "" {
-		conditions = append(conditions, subjectConditions)
+// 新的报表查询接口 - 使用新表结构
+func (db *ClickHouseDB) GetReportDataNew(q *models.ReportQuery) (*models.Response, error) {
+	log.Printf("报表查询参数: %+v", *q)
+	f := db.buildReportFilter(q)
+
+	var matched []models.AdmissionHubeiWide
+	var totalCount int64
+	var err error
+	if q.GroupByMajorGroup {
+		matched, totalCount, err = db.queryGroupPage(f, q.Page, q.PageSize)
+	} else {
+		matched, totalCount, err = db.queryRowPage(f, q.Page, q.PageSize)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. 二次筛选：院校所在省份
-	if len(collegeLocation) > 0 {
-		locationConditions := make([]string, len(collegeLocation))
-		for i, location := range collegeLocation {
-			locationConditions[i] = fmt.Sprintf("school_province = $%d", argIndex)
-			args = append(args, location)
-			argIndex++
-		}
-		conditions = append(conditions, fmt.Sprintf("(%s)", strings.Join(locationConditions, " OR ")))
+	list := make([]models.List, 0, len(matched))
+	for i := range matched {
+		list = append(list, buildReportItem(db.ranks, &matched[i], q.Province, q.ClassFirstChoice))
 	}
+	log.Printf("查询到 %d 条符合条件的记录", len(list))
 
-	// 3. 三次筛选：意向专业方向
-	if len(interest) > 0 {
-		interestConditions := db.buildInterestConditions(interest)
-		if interestConditions != "" {
-			conditions = append(conditions, interestConditions)
-		}
+	// 附加历年录取数据
+	fromYear, toYear := historyYearRange(q.Year, q.HistoryYears)
+	history, err := db.GetAdmissionHistory(q.Province, q.ClassFirstChoice, fromYear, toYear, distinctSchoolCodes(matched))
+	if err != nil {
+		log.Printf("查询历年录取数据失败: %v", err)
 	}
+	attachHistory(list, matched, history)
+	attachEquivalentScores(db.ranks, list, q)
 
-	// 4. 模糊专业名称筛选
-	if fuzzySubjectCategory != "" {
-		conditions = append(conditions, fmt.Sprintf("major_name LIKE $%d", argIndex))
-		args = append(args, "%"+fuzzySubjectCategory+"%")
-		argIndex++
-	}
-
-	// 5. 分数（省生源地排位）筛选 - 冲稳保策略
-	var upperScore, lowerScore int64
-	var minScoreDiff, maxScoreDiff int64
-
-	switch strategy {
-	case 0: // 冲
-		minScoreDiff = 3  // 分数比最低分高3分
-		maxScoreDiff = 20 // 分数比最低分高20分
-	case 1: // 稳
-		minScoreDiff = -5 // 分数比最低分低5分
-		maxScoreDiff = 3  // 分数比最低分高3分
-	case 2: // 保
-		minScoreDiff = -20 // 分数比最低分低20分
-		maxScoreDiff = -5  // 分数比最低分低5分
-	default: // 冲稳保混合
-		minScoreDiff = -20 // 从保到冲的完整范围
-		maxScoreDiff = 20
-	}
-
-	// 计算实际分数范围
-	rankScore := int64(rankScoreUint16)
-	lowerScore = rankScore
-	upperScore = rankScore
-
-	if minScoreDiff >= 0 {
-		lowerScore = rankScore + minScoreDiff
-	} else {
-		if -minScoreDiff <= rankScore {
-			lowerScore = rankScore - (-minScoreDiff)
-		} else {
-			lowerScore = 0 // 防止下溢
-		}
+	return buildReport(q, list, matched, totalCount), nil
+}
+
+// 读取全部符合条件的报表行（不分页、不附加历年数据），按录取分降序，每 StreamBatchSize 行调用一次 fn。
+// 数据从查询游标逐块读取，内存占用与结果行数无关；fn 返回错误或 ctx 取消时停止查询
+func (db *ClickHouseDB) StreamReport(ctx context.Context, q *models.ReportQuery, fn func(batch []models.List) error) error {
+	f := db.buildReportFilter(q)
+	dataQuery, args := f.selectRows().OrderBy(f.scoreColumn + " DESC, " + codeOrder).Build()
+	log.Printf("执行导出查询: %s, args: %v", dataQuery, args)
+	rows, err := db.conn.Query(ctx, dataQuery, args...)
+	if err != nil {
+		log.Printf("导出查询失败: %v", err)
+		return err
 	}
+	defer rows.Close()
 
-	if maxScoreDiff >= 0 {
-		upperScore = rankScore + maxScoreDiff
-	} else {
-		if -maxScoreDiff <= rankScore {
-			upperScore = rankScore - (-maxScoreDiff)
-		} else {
-			upperScore = 0 // 防止下溢
+	batch := make([]models.List, 0, StreamBatchSize)
+	for rows.Next() {
+		var row models.AdmissionHubeiWide
+		// 跳过无法扫描的行会导出不完整的数据，中断导出
+		if err := scanReportRow(rows, &row); err != nil {
+			log.Printf("扫描导出行数据错误: %v", err)
+			return err
+		}
+		batch = append(batch, buildReportItem(db.ranks, &row, q.Province, q.ClassFirstChoice))
+		if len(batch) == StreamBatchSize {
+			if err := flushStreamBatch(db.ranks, batch, q, fn); err != nil {
+				return err
+			}
+			batch = batch[:0]
 		}
 	}
-
-	conditions = append(conditions, fmt.Sprintf("min_score_2024 BETWEEN $%d AND $%d", argIndex, argIndex+1))
-	args = append(args, lowerScore, upperScore)
-	argIndex += 2
-
-	// 构建WHERE子句
-	var whereClause string
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	} else {
-		whereClause = ""
+	if err := rows.Err(); err != nil {
+		return err
 	}
+	return flushStreamBatch(db.ranks, batch, q, fn)
+}
 
-	// 查询总数
-	countQuery := fmt.Sprintf(`
-		SELECT COUNT(*) AS total_count 
-		FROM default.gaokao2025 
-		%s
-	`, whereClause)
+// 同分时的排序，保证分页和导出的顺序稳定，与 MemoryDB 的 lessByCode 一致
+const codeOrder = "school_code, major_group_code, major_code"
 
+// 按专业分页查询报表行，返回当页数据和符合条件的总行数
+func (db *ClickHouseDB) queryRowPage(f *reportFilter, page, pageSize int64) ([]models.AdmissionHubeiWide, int64, error) {
+	countQuery, args := f.selectFrom("COUNT(*) AS total_count").Build()
 	log.Printf("执行计数查询: %s, args: %v", countQuery, args)
-	var totalCountUint uint64
-	err = db.conn.QueryRow(context.Background(), countQuery, args...).Scan(&totalCountUint)
-	if err != nil {
+	var totalCount uint64
+	if err := db.conn.QueryRow(context.Background(), countQuery, args...).Scan(&totalCount); err != nil {
 		log.Printf("计数查询失败: %v", err)
-		totalCountUint = 0
+		totalCount = 0
 	}
-	totalCount := int64(totalCountUint)
 	log.Printf("查询到符合条件的记录总数: %d", totalCount)
 
-	// 计算分页
-	offset := (page - 1) * pageSize
-	totalPages := int64(0)
-	if totalCount > 0 {
-		totalPages = (totalCount + pageSize - 1) / pageSize
+	matched, err := db.queryReportRows(rowPageQuery(f, page, pageSize))
+	return matched, int64(totalCount), err
+}
+
+// 当页报表行，按录取分降序、同分按代码排列
+func rowPageQuery(f *reportFilter, page, pageSize int64) *querybuilder.SelectBuilder {
+	return f.selectRows().OrderBy(f.scoreColumn + " DESC, " + codeOrder).Limit(pageSize).Offset((page - 1) * pageSize)
+}
+
+// 按院校专业组分页查询报表行：先取当页的专业组，再查询这些专业组内符合条件的专业，
+// 返回当页数据和符合条件的专业组总数
+func (db *ClickHouseDB) queryGroupPage(f *reportFilter, page, pageSize int64) ([]models.AdmissionHubeiWide, int64, error) {
+	countQuery, args := querybuilder.Select("COUNT(*)").
+		FromSubquery(f.selectFrom("school_code", "major_group_code").GroupBy("school_code", "major_group_code")).
+		Build()
+	log.Printf("执行专业组计数查询: %s, args: %v", countQuery, args)
+	var totalCount uint64
+	if err := db.conn.QueryRow(context.Background(), countQuery, args...).Scan(&totalCount); err != nil {
+		log.Printf("专业组计数查询失败: %v", err)
+		totalCount = 0
+	}
+
+	keys, err := db.queryGroupKeys(groupPageQuery(f, page, pageSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(keys) == 0 {
+		return nil, int64(totalCount), nil
 	}
 
-	// 查询数据
-	dataQuery := fmt.Sprintf(`
-		SELECT id, school_name, school_code, major_group_code, 
-			   subject_requirement_raw, school_province, school_city, 
-			   school_ownership, school_type, school_authority, school_level, 
-			   school_tags, education_level, major_description, tuition_fee, is_new_major,
-			   min_score_2024, min_rank_2024, major_name, study_duration, major_min_score_2024
-		FROM default.gaokao2025 
-		%s
-		ORDER BY min_score_2024 DESC
-		LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+	// 只查询当页专业组内的专业，按专业组的顺序排列
+	matched, err := db.queryReportRows(f.selectRows().
+		Where(querybuilder.In("concat(school_code, '|', major_group_code)", keys)).
+		OrderBy("indexOf(?, concat(school_code, '|', major_group_code))", keys).
+		OrderBy("major_min_score_2024 DESC, major_code"))
+	return matched, int64(totalCount), err
+}
 
-	args = append(args, pageSize, offset)
+// 当页的专业组，按组内最高录取分降序，同分按院校代码、专业组代码
+func groupPageQuery(f *reportFilter, page, pageSize int64) *querybuilder.SelectBuilder {
+	return f.selectFrom("concat(school_code, '|', major_group_code) AS group_key").
+		GroupBy("school_code", "major_group_code").
+		OrderBy("max(" + f.scoreColumn + ") DESC, school_code, major_group_code").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize)
+}
 
-	log.Printf("执行数据查询: %s", dataQuery)
-	rows, err := db.conn.Query(context.Background(), dataQuery, args...)
+// 查询当页的专业组，返回 院校代码|专业组代码
+func (db *ClickHouseDB) queryGroupKeys(query *querybuilder.SelectBuilder) ([]string, error) {
+	groupQuery, args := query.Build()
+	rows, err := db.conn.Query(context.Background(), groupQuery, args...)
 	if err != nil {
-		log.Printf("数据查询失败: %v", err)
+		log.Printf("专业组查询失败: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var list []models.List
+	var keys []string
 	for rows.Next() {
-		var item models.List
-		var id uint32
-		var schoolName, schoolCode, groupCode, subjectReq, schoolProvince, schoolCity string
-		var schoolOwnership, schoolType, schoolAuthority, schoolLevel, schoolTags string
-		var educationLevel, majorDesc, majorName string
-		var tuitionFee string
-		var isNewMajor bool
-		var minScore uint16
-		var minRank uint32
-		var studyDuration sql.NullString
-		var majorMinScore uint16
-
-		err := rows.Scan(&id, &schoolName, &schoolCode, &groupCode, &subjectReq,
-			&schoolProvince, &schoolCity, &schoolOwnership, &schoolType, &schoolAuthority,
-			&schoolLevel, &schoolTags, &educationLevel, &majorDesc, &tuitionFee, &isNewMajor,
-			&minScore, &minRank, &majorName, &studyDuration, &majorMinScore)
-		if err != nil {
-			log.Printf("扫描行数据错误: %v", err)
-			continue
-		}
-
-		// 处理学制字段
-		var studyYearsPtr *string
-		if studyDuration.Valid {
-			studyYearsPtr = &studyDuration.String
-		}
-
-		// 处理专业最低分字段 - 现在是非指针类型
-		var majorMinScorePtr *uint16
-		if majorMinScore > 0 {
-			majorMinScorePtr = &majorMinScore
-		}
-
-		// 计算专业最低分对应的2024年排名
-		var majorMinRank2024Ptr *int
-		if majorMinScore > 0 {
-			// 直接使用用户选择的首选科目类型
-			subjectType := classFirstChoice
-			// 使用专业最低分计算2024年排名
-			rank2024 := GetRankByScore2024(int(majorMinScore), subjectType)
-			majorMinRank2024Ptr = &rank2024
-		}
-
-		// 处理学费字段 - 转换为uint32
-		var tuitionFeeUint32 *uint32
-		if tuitionFee != "" {
-			if fee, err := strconv.ParseUint(tuitionFee, 10, 32); err == nil {
-				feeUint32 := uint32(fee)
-				tuitionFeeUint32 = &feeUint32
-			}
-		}
-
-		// 转换数据类型 - 确保类型匹配
-		idUint64 := uint64(id)
-		lowestPointsInt64 := int64(minScore)
-		lowestRankInt64 := int64(minRank)
-
-		item = models.List{
-			ID:                       &idUint64,
-			CollegeName:              &schoolName,
-			CollegeCode:              &schoolCode,
-			SpecialInterestGroupCode: &groupCode,
-			ClassDemand:              &subjectReq,
-			CollegeProvince:          &schoolProvince,
-			CollegeCity:              &schoolCity,
-			CollegeOwnership:         &schoolOwnership,
-			CollegeType:              &schoolType,
-			CollegeAuthority:         &schoolAuthority,
-			CollegeLevel:             &schoolLevel,
-			CollegeTags:              &schoolTags,
-			EducationLevel:           &educationLevel,
-			MajorDescription:         &majorDesc,
-			TuitionFee:               tuitionFeeUint32,
-			IsNewMajor:               &isNewMajor,
-			LowestPoints:             &lowestPointsInt64,
-			LowestRank:               &lowestRankInt64,
-			ProfessionalName:         majorName,
-			StudyYears:               studyYearsPtr,
-			MajorMinScore2024:        majorMinScorePtr,
-			MajorMinRank2024:         majorMinRank2024Ptr,
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
 		}
-		list = append(list, item)
+		keys = append(keys, key)
 	}
-	log.Printf("查询到 %d 条符合条件的记录", len(list))
-
-	conf := &models.Conf{
-		Page:        page,
-		PageSize:    pageSize,
-		TotalNumber: totalCount,
-		TotalPage:   totalPages,
+	if err := rows.Err(); err != nil {
+		log.Printf("专业组查询失败: %v", err)
+		return nil, err
 	}
-
-	return &models.Response{
-		Code: 0,
-		Msg:  "success",
-		Data: models.Data{
-			Conf: conf,
-			List: list,
-		},
-	}, nil
+	return keys, nil
 }
 
-// 构建选科条件
-func (db *ClickHouseDB) buildSubjectConditions(classFirstChoice string, classOptionalChoice []string) string {
-	var conditions []string
+// 查询所有符合条件的录取数据（不分页），按参考年份专业组录取位次升序
+func (db *ClickHouseDB) ListAdmissions(q *models.ReportQuery) ([]models.AdmissionHubeiWide, error) {
+	f := db.buildReportFilter(q)
+	return db.queryReportRows(f.selectRows().OrderBy(f.rankColumn + " ASC, " + codeOrder))
+}
 
-	// 首选科目条件 - 使用subject_category字段匹配
-	if classFirstChoice == "物理" {
-		conditions = append(conditions, "subject_category = '物理'")
-	} else if classFirstChoice == "历史" {
-		conditions = append(conditions, "subject_category = '历史'")
+// 查询院校专业组内的全部专业，按专业组、专业最低分升序
+func (db *ClickHouseDB) GetGroupMajors(province, subjectCategory, batch string, groups []models.MajorGroupRef) ([]models.AdmissionHubeiWide, error) {
+	if len(groups) == 0 {
+		return nil, nil
 	}
+	return db.queryReportRows(groupMajorsQuery(province, subjectCategory, batch, groups))
+}
 
-	// 可选科目条件 - 简化逻辑：用户选择的科目能够满足专业要求
-	if len(classOptionalChoice) > 0 {
-		// 解析可选科目
-		subjectMap := map[string]string{
-			"化学": "require_chemistry",
-			"生物": "require_biology",
-			"政治": "require_politics",
-			"历史": "require_history",
-			"地理": "require_geography",
-		}
+// 查询院校在某科类、批次的全部专业，按专业组、专业代码升序
+func (db *ClickHouseDB) GetSchoolMajors(province, subjectCategory, batch, schoolCode string) ([]models.AdmissionHubeiWide, error) {
+	return db.queryReportRows(schoolMajorsQuery(province, subjectCategory, batch, schoolCode))
+}
 
-		// 用户没有选择的科目，专业不能要求
-		userSelectedSubjects := make(map[string]bool)
-		for _, subject := range classOptionalChoice {
-			userSelectedSubjects[subject] = true
-		}
+func groupMajorsQuery(province, subjectCategory, batch string, groups []models.MajorGroupRef) *querybuilder.SelectBuilder {
+	f := wideTableFilter(province, subjectCategory, batch,
+		querybuilder.In("concat(school_code, '|', major_group_code)", groupKeys(groups)))
+	return f.selectRows().OrderBy("school_code, major_group_code, major_min_score_2024")
+}
 
-		var subjectConditions []string
-		allSubjects := []string{"化学", "生物", "政治", "历史", "地理"}
-		for _, subject := range allSubjects {
-			if field, exists := subjectMap[subject]; exists {
-				if userSelectedSubjects[subject] {
-					// 用户选择了这个科目，专业可以要求也可以不要求
-					// 不添加限制条件
-				} else {
-					// 用户没有选择这个科目，专业不能要求
-					subjectConditions = append(subjectConditions, fmt.Sprintf("%s = false", field))
-				}
-			}
-		}
+func schoolMajorsQuery(province, subjectCategory, batch, schoolCode string) *querybuilder.SelectBuilder {
+	f := wideTableFilter(province, subjectCategory, batch, querybuilder.Eq("school_code", schoolCode))
+	return f.selectRows().OrderBy("major_group_code, major_code")
+}
 
-		if len(subjectConditions) > 0 {
-			conditions = append(conditions, "("+strings.Join(subjectConditions, " AND ")+")")
-		}
+// 宽表中某科类、批次的录取数据，不受报表筛选条件影响
+func wideTableFilter(province, subjectCategory, batch string, conditions ...querybuilder.Cond) *reportFilter {
+	return &reportFilter{
+		from: querybuilder.Expr("gaokao2025"),
+		where: append([]querybuilder.Cond{
+			querybuilder.Eq("source_province", province),
+			querybuilder.Eq("subject_category", subjectCategory),
+			querybuilder.Eq("admission_batch", batch),
+		}, conditions...),
+		scoreColumn: "min_score_2024",
+		rankColumn:  "min_rank_2024",
 	}
+}
 
-	if len(conditions) > 0 {
-		return strings.Join(conditions, " AND ")
+// 构建选科条件（科类条件由调用方按参数绑定），与 SubjectEligible 一致：首选科目也视为已选。
+// 有 subject_requirement 时选科组合需包含其中任一组科目；没有解析结果的旧数据按 require_* 字段，
+// 考生没有选的科目，专业不能要求
+func (db *ClickHouseDB) buildSubjectConditions(classFirstChoice string, classOptionalChoice []string) querybuilder.Cond {
+	if len(classOptionalChoice) == 0 {
+		return querybuilder.Cond{}
 	}
-	return ""
+	return subjectCombinationCond(append([]string{classFirstChoice}, classOptionalChoice...))
 }
 
-// 构建专业兴趣条件
-func (db *ClickHouseDB) buildInterestConditions(interests []string) string {
-	interestMap := map[string][]string{
-		"理科":     {"数学", "物理", "化学", "生物", "天文", "地理", "统计"},
-		"工科":     {"工程", "机械", "电子", "计算机", "软件", "土木", "建筑", "材料"},
-		"文科":     {"文学", "历史", "哲学", "语言", "新闻", "传播", "艺术"},
-		"经管法":    {"经济", "管理", "商务", "金融", "法学", "法律", "会计"},
-		"医科":     {"医学", "临床", "护理", "药学", "中医", "口腔"},
-		"设计与艺术类": {"设计", "艺术", "美术", "音乐", "舞蹈", "戏剧"},
-		"语言类":    {"英语", "日语", "法语", "德语", "俄语", "西班牙语", "阿拉伯语"},
+// 选科组合 combination 满足选科要求的条件
+func subjectCombinationCond(combination []string) querybuilder.Cond {
+	userSelectedSubjects := make(map[string]bool)
+	for _, subject := range combination {
+		userSelectedSubjects[subject] = true
 	}
 
-	var conditions []string
-	for _, interest := range interests {
-		if keywords, exists := interestMap[interest]; exists {
-			var keywordConditions []string
-			for _, keyword := range keywords {
-				keywordConditions = append(keywordConditions, fmt.Sprintf("major_name LIKE '%%%s%%'", keyword))
-			}
-			if len(keywordConditions) > 0 {
-				conditions = append(conditions, "("+strings.Join(keywordConditions, " OR ")+")")
-			}
+	legacy := []querybuilder.Cond{querybuilder.Expr("empty(subject_requirement)")}
+	for _, item := range subjectFields {
+		if !userSelectedSubjects[item.Subject] {
+			legacy = append(legacy, querybuilder.Expr(item.Field+" = false"))
 		}
 	}
+	return querybuilder.Or(
+		querybuilder.Expr("arrayExists(c -> hasAll(?, c), subject_requirement)", combination),
+		querybuilder.And(legacy...),
+	)
+}
 
-	if len(conditions) > 0 {
-		return "(" + strings.Join(conditions, " OR ") + ")"
+// 构建专业兴趣条件，flags 为 is_* 标签字段，只接受gaokao2025中的标签字段
+func (db *ClickHouseDB) buildInterestConditions(flags []string) querybuilder.Cond {
+	var conditions []querybuilder.Cond
+	for _, flag := range flags {
+		if taxonomy.IsFlagColumn(flag) {
+			conditions = append(conditions, querybuilder.Expr(flag+" = true"))
+		}
 	}
-	return ""
+	return querybuilder.Or(conditions...)
 }
 
 // 查询报表数据
 func (db *ClickHouseDB) GetReportData(rank int64, classComb string, province string, page, pageSize int64) (*models.Response, error) {
 	// 获取2024年对应位次的分数
-	var rankScore int64
-	scoreQuery := `
-	SELECT lowest_points FROM gaokao.admission_data 
-	WHERE year = 2024 AND lowest_rank <= ? AND lowest_rank > 0
-	ORDER BY lowest_rank DESC 
-	LIMIT 1
-	`
+	var provinceConditions []querybuilder.Cond
+	if province != "" {
+		provinceConditions = append(provinceConditions, querybuilder.Eq("source_province", province))
+	}
+	var rankScore uint16
+	scoreQuery, scoreArgs := querybuilder.Select("min_score_2024").
+		From("gaokao2025").
+		Where(append(provinceConditions, querybuilder.Expr("min_rank_2024 <= ?", rank), querybuilder.Expr("min_rank_2024 > 0"))...).
+		OrderBy("min_rank_2024 DESC").
+		Limit(1).
+		Build()
 
-	row := db.conn.QueryRow(context.Background(), scoreQuery, rank)
+	row := db.conn.QueryRow(context.Background(), scoreQuery, scoreArgs...)
 	err := row.Scan(&rankScore)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// 如果没有找到精确位次，查询附近的位次
-			nearbyQuery := `
-			SELECT lowest_points FROM gaokao.admission_data 
-			WHERE year = 2024 AND lowest_rank > 0
-			ORDER BY ABS(lowest_rank - ?)
-			LIMIT 1
-			`
-			row = db.conn.QueryRow(context.Background(), nearbyQuery, rank)
+			nearbyQuery, nearbyArgs := querybuilder.Select("min_score_2024").
+				From("gaokao2025").
+				Where(append(provinceConditions, querybuilder.Expr("min_rank_2024 > 0"))...).
+				OrderBy("abs(toInt64(min_rank_2024) - ?)", rank).
+				Limit(1).
+				Build()
+			row = db.conn.QueryRow(context.Background(), nearbyQuery, nearbyArgs...)
 			err = row.Scan(&rankScore)
 			if err != nil {
-				log.Printf("无法找到位次 %d 附近的数据，使用默认分数 500", rank)
-				rankScore = 500 // 默认分数
+				return nil, fmt.Errorf("无法找到位次 %d 对应的分数", rank)
 			} else {
 				log.Printf("找到位次 %d 附近的数据，对应分数为 %d", rank, rankScore)
 			}
@@ -746,97 +594,46 @@ func (db *ClickHouseDB) GetReportData(rank int64, classComb string, province str
 	}
 
 	// 计算分数范围：在位次对应分数基础上，上浮+20分，下浮-30分
-	upperScore := rankScore + 20
-	lowerScore := rankScore - 30
+	upperScore := int64(rankScore) + 20
+	lowerScore := int64(rankScore) - 30
 	if lowerScore < 0 {
 		lowerScore = 0
 	}
 	log.Printf("分数范围设置为 %d-%d", lowerScore, upperScore)
 
-	// 构建选科条件
-	classCondition := buildClassCondition(classComb)
-
-	// 构建省份条件
-	provinceCondition := ""
-	if province != "" {
-		provinceCondition = fmt.Sprintf("AND province = '%s'", province)
-		log.Printf("添加省份筛选条件: %s", province)
-	}
-
-	// 查询总数
-	countQuery := fmt.Sprintf(`
-	SELECT COUNT(*) AS total_count FROM gaokao.admission_data 
-	WHERE year = 2024 
-	AND lowest_points BETWEEN ? AND ? 
-	%s
-	%s
-	`, classCondition, provinceCondition)
-
-	log.Printf("执行计数查询: %s", countQuery)
-	var totalCountUint uint64 // 使用uint64接收COUNT()结果
-	err = db.conn.QueryRow(context.Background(), countQuery, lowerScore, upperScore).Scan(&totalCountUint)
+	// 筛选、计数和分页都在SQL中完成
+	f := legacyReportFilter(lowerScore, upperScore, province, parseClassComb(classComb))
+	matched, totalCount, err := db.queryRowPage(f, page, pageSize)
 	if err != nil {
-		log.Printf("计数查询失败: %v", err)
-		totalCountUint = 0
+		return nil, err
 	}
-	totalCount := int64(totalCountUint) // 转换为int64
-	log.Printf("查询到符合条件的记录总数: %d", totalCount)
 
 	// 计算分页
-	offset := (page - 1) * pageSize
 	totalPages := int64(0)
 	if totalCount > 0 {
 		totalPages = (totalCount + pageSize - 1) / pageSize
 	}
-	log.Printf("分页信息: 当前页=%d, 每页条数=%d, 总页数=%d, 偏移量=%d",
-		page, pageSize, totalPages, offset)
-
-	// 查询数据
-	dataQuery := fmt.Sprintf(`
-	SELECT id, college_code, college_name, special_interest_group_code, 
-		   professional_name, class_demand, lowest_points, lowest_rank, description
-	FROM gaokao.admission_data 
-	WHERE year = 2024 
-	AND lowest_points BETWEEN ? AND ? 
-	%s
-	%s
-	ORDER BY lowest_points DESC
-	LIMIT ? OFFSET ?
-	`, classCondition, provinceCondition)
-
-	log.Printf("执行数据查询: %s", dataQuery)
-	rows, err := db.conn.Query(context.Background(), dataQuery, lowerScore, upperScore, pageSize, offset)
-	if err != nil {
-		log.Printf("数据查询失败: %v", err)
-		return nil, err
-	}
-	defer rows.Close()
+	log.Printf("分页信息: 当前页=%d, 每页条数=%d, 总页数=%d",
+		page, pageSize, totalPages)
 
 	var list []models.List
-	for rows.Next() {
-		var item models.List
-		var id uint64
-		var collegeCode, collegeName, groupCode, profName, classDemand, desc string
-		var lowestPoints, lowestRank int64
-
-		err := rows.Scan(&id, &collegeCode, &collegeName, &groupCode, &profName, &classDemand, &lowestPoints, &lowestRank, &desc)
-		if err != nil {
-			log.Printf("扫描行数据错误: %v", err)
-			continue
-		}
-
-		item = models.List{
+	for i := range matched {
+		r := &matched[i]
+		id := uint64(r.ID)
+		lowestPoints := int64(r.MinScore2024)
+		lowestRank := int64(r.MinRank2024)
+		list = append(list, models.List{
 			ID:                       &id,
-			CollegeCode:              &collegeCode,
-			CollegeName:              &collegeName,
-			SpecialInterestGroupCode: &groupCode,
-			ProfessionalName:         profName,
-			ClassDemand:              &classDemand,
+			CollegeCode:              &r.SchoolCode,
+			CollegeName:              &r.SchoolName,
+			SpecialInterestGroupCode: &r.MajorGroupCode,
+			MajorCode:                &r.MajorCode,
+			ProfessionalName:         r.MajorName,
+			ClassDemand:              &r.SubjectRequirementRaw,
 			LowestPoints:             &lowestPoints,
 			LowestRank:               &lowestRank,
-			Description:              &desc,
-		}
-		list = append(list, item)
+			Description:              &r.MajorDescription,
+		})
 	}
 	log.Printf("查询到 %d 条符合条件的记录", len(list))
 
@@ -857,59 +654,45 @@ func (db *ClickHouseDB) GetReportData(rank int64, classComb string, province str
 	}, nil
 }
 
-// 构建选科条件
-func buildClassCondition(classComb string) string {
-	if classComb == "" {
-		log.Printf("未提供选科组合，不添加选科筛选条件")
-		return ""
+// 旧版报表的筛选条件：2024年专业组最低分在分数范围内，选科按 subject_requirement / require_* 字段匹配
+func legacyReportFilter(lowerScore, upperScore int64, province string, combination []string) *reportFilter {
+	conditions := []querybuilder.Cond{querybuilder.Between("min_score_2024", lowerScore, upperScore)}
+	if province != "" {
+		conditions = append(conditions, querybuilder.Eq("source_province", province))
+		log.Printf("添加省份筛选条件: %s", province)
+	}
+	if len(combination) > 0 {
+		conditions = append(conditions, subjectCombinationCond(combination))
+	}
+	return &reportFilter{
+		from:        querybuilder.Expr("gaokao2025"),
+		where:       conditions,
+		scoreColumn: "min_score_2024",
+		rankColumn:  "min_rank_2024",
 	}
+}
 
+// 解析数字编码的选科组合，如 "123" 为物理、化学、生物
+func parseClassComb(classComb string) []string {
 	// 移除引号
 	classComb = strings.Trim(classComb, "\"")
-
-	// 调试输出
-	log.Printf("处理选科组合: %s", classComb)
+	if classComb == "" {
+		log.Printf("未提供选科组合，不添加选科筛选条件")
+		return nil
+	}
 
 	// 物理、化学、生物、政治、历史、地理
 	// 1     2     3     4     5     6
-	subjectMap := map[string]string{
-		"1": "物",
-		"2": "化",
-		"3": "生",
-		"4": "政",
-		"5": "历",
-		"6": "地",
-	}
-
 	var subjects []string
 	for _, char := range classComb {
-		if subject, exists := subjectMap[string(char)]; exists {
-			subjects = append(subjects, subject)
+		if n := int(char - '1'); n >= 0 && n < len(subjectFields) {
+			subjects = append(subjects, subjectFields[n].Subject)
 		}
 	}
-
 	if len(subjects) == 0 {
 		log.Printf("选科组合 %s 无法识别任何有效科目，不添加选科筛选条件", classComb)
-		return ""
-	}
-
-	log.Printf("选择科目: %v", subjects)
-
-	// 构建SQL条件，选科要求包含用户选的科目或者不限
-	var conditions []string
-
-	// 添加包含所有用户选科的条件
-	for _, subject := range subjects {
-		conditions = append(conditions, fmt.Sprintf("class_demand LIKE '%%%s%%'", subject))
 	}
-
-	// 添加不限选项
-	conditions = append(conditions, "class_demand = '不限'", "class_demand = ''")
-
-	conditionStr := fmt.Sprintf("AND (%s)", strings.Join(conditions, " OR "))
-	log.Printf("构建的选科SQL条件: %s", conditionStr)
-
-	return conditionStr
+	return subjects
 }
 
 // 获取数据记录数
@@ -922,9 +705,3 @@ func (db *ClickHouseDB) GetDataCount() (int64, error) {
 	}
 	return count, nil
 }
-
-// 根据分数查询位次（简化版，不考虑科类和选科条件）
-func (db *ClickHouseDB) QueryRankByScoreSimple(province string, year int, score float64) (int64, error) {
-	// 使用新表查询，默认查询物理类
-	return db.QueryRankByScoreNew(score, "物理")
-}
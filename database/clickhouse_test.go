@@ -0,0 +1,285 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+// 报表查询的基础条件：生源省份、科类和批次
+const baseWhere = "source_province = $1 AND subject_category = $2 AND has($3, admission_batch)"
+
+func baseQuery() *models.ReportQuery {
+	return &models.ReportQuery{
+		Year:             models.ReferenceAdmissionYear,
+		Province:         "湖北",
+		ClassFirstChoice: "物理",
+		Batch:            "本科批",
+	}
+}
+
+func TestBuildReportFilter(t *testing.T) {
+	baseArgs := []interface{}{"湖北", "物理", []string{"本科批"}}
+	tests := []struct {
+		name   string
+		modify func(q *models.ReportQuery)
+		from   string
+		where  string
+		args   []interface{}
+	}{
+		{
+			name:  "base",
+			where: baseWhere,
+			args:  baseArgs,
+		},
+		{
+			name: "optional subjects",
+			modify: func(q *models.ReportQuery) {
+				q.ClassOptionalChoice = []string{"化学", "生物"}
+			},
+			where: baseWhere + " AND (arrayExists(c -> hasAll($4, c), subject_requirement)" +
+				" OR (empty(subject_requirement) AND require_politics = false AND require_history = false AND require_geography = false))",
+			args: append(baseArgs, []string{"物理", "化学", "生物"}),
+		},
+		{
+			name: "college location",
+			modify: func(q *models.ReportQuery) {
+				q.CollegeLocation = []string{"湖北", "北京"}
+			},
+			where: baseWhere + " AND has($4, school_province)",
+			args:  append(baseArgs, []string{"湖北", "北京"}),
+		},
+		{
+			name: "interest flags skip unknown columns",
+			modify: func(q *models.ReportQuery) {
+				q.InterestFlags = []string{"is_engineering", "major_name = '' OR 1", "is_medical"}
+			},
+			where: baseWhere + " AND (is_engineering = true OR is_medical = true)",
+			args:  baseArgs,
+		},
+		{
+			name: "major categories",
+			modify: func(q *models.ReportQuery) {
+				q.MajorCategories = []string{"0809"}
+			},
+			where: baseWhere + " AND has($4, substring(major_code, 1, 4))",
+			args:  append(baseArgs, []string{"0809"}),
+		},
+		{
+			name: "fuzzy major name",
+			modify: func(q *models.ReportQuery) {
+				q.FuzzySubjectCategory = "计算机' OR '1'='1"
+			},
+			where: baseWhere + " AND major_name LIKE $4",
+			args:  append(baseArgs, "%计算机' OR '1'='1%"),
+		},
+		{
+			name: "major code",
+			modify: func(q *models.ReportQuery) {
+				q.MajorCode = "100201K"
+			},
+			where: baseWhere + " AND major_code = $4",
+			args:  append(baseArgs, "100201K"),
+		},
+		{
+			name: "rank window",
+			modify: func(q *models.ReportQuery) {
+				q.MinCutoffRank, q.MaxCutoffRank = 4800, 7200
+			},
+			where: baseWhere + " AND min_rank_2024 BETWEEN $4 AND $5",
+			args:  append(baseArgs, int64(4800), int64(7200)),
+		},
+		{
+			name: "score window",
+			modify: func(q *models.ReportQuery) {
+				q.MinCutoffScore, q.MaxCutoffScore = 612, 635
+			},
+			where: baseWhere + " AND min_score_2024 BETWEEN $4 AND $5",
+			args:  append(baseArgs, int64(612), int64(635)),
+		},
+		{
+			name: "other reference year joins history first",
+			modify: func(q *models.ReportQuery) {
+				q.Year = 2023
+				q.MinCutoffRank, q.MaxCutoffRank = 4800, 7200
+			},
+			from:  "admission_history FINAL",
+			where: "source_province = $2 AND subject_category = $3 AND has($4, admission_batch) AND h_min_rank BETWEEN $5 AND $6",
+			args:  []interface{}{2023, "湖北", "物理", []string{"本科批"}, int64(4800), int64(7200)},
+		},
+		{
+			name: "school tags",
+			modify: func(q *models.ReportQuery) {
+				q.Filters.SchoolTags = models.StringFilter{Include: []string{"985", "211"}, Exclude: []string{"民办"}}
+			},
+			where: baseWhere + " AND (positionUTF8(school_tags, $4) > 0 OR positionUTF8(school_tags, $5) > 0) AND positionUTF8(school_tags, $6) = 0",
+			args:  append(baseArgs, "985", "211", "民办"),
+		},
+		{
+			name: "string filters",
+			modify: func(q *models.ReportQuery) {
+				q.Filters.SchoolOwnership = models.StringFilter{Include: []string{"公办"}}
+				q.Filters.SchoolCity = models.StringFilter{Exclude: []string{"武汉"}}
+			},
+			where: baseWhere + " AND has($4, school_ownership) AND NOT has($5, school_city)",
+			args:  append(baseArgs, []string{"公办"}, []string{"武汉"}),
+		},
+		{
+			name: "batch include replaces batch and exclude is negated",
+			modify: func(q *models.ReportQuery) {
+				q.Filters.AdmissionBatch = models.StringFilter{Include: []string{"本科批", "专科批"}, Exclude: []string{"提前批"}}
+			},
+			where: baseWhere + " AND NOT has($4, admission_batch)",
+			args:  []interface{}{"湖北", "物理", []string{"本科批", "专科批"}, []string{"提前批"}},
+		},
+		{
+			name: "tuition range",
+			modify: func(q *models.ReportQuery) {
+				q.Filters.TuitionFee = models.RangeFilter{Min: int64Ptr(4000), Max: int64Ptr(10000)}
+			},
+			where: baseWhere + " AND toUInt32OrNull(tuition_fee) IS NOT NULL AND toUInt32OrZero(tuition_fee) >= $4 AND toUInt32OrZero(tuition_fee) <= $5",
+			args:  append(baseArgs, int64(4000), int64(10000)),
+		},
+		{
+			name: "study duration max and new major",
+			modify: func(q *models.ReportQuery) {
+				q.Filters.StudyDuration = models.RangeFilter{Max: int64Ptr(4)}
+				q.Filters.IsNewMajor = boolPtr(false)
+			},
+			where: baseWhere + " AND study_duration <= $4 AND is_new_major = $5",
+			args:  append(baseArgs, int64(4), false),
+		},
+		{
+			name: "all filters",
+			modify: func(q *models.ReportQuery) {
+				q.ClassOptionalChoice = []string{"化学", "地理"}
+				q.CollegeLocation = []string{"湖北"}
+				q.InterestFlags = []string{"is_science"}
+				q.MajorCategories = []string{"0701"}
+				q.FuzzySubjectCategory = "数学"
+				q.MinCutoffRank, q.MaxCutoffRank = 1, 9000
+				q.Filters.SchoolLevel = models.StringFilter{Include: []string{"双一流"}}
+				q.Filters.TuitionFee = models.RangeFilter{Max: int64Ptr(6000)}
+			},
+			where: baseWhere +
+				" AND (arrayExists(c -> hasAll($4, c), subject_requirement)" +
+				" OR (empty(subject_requirement) AND require_biology = false AND require_politics = false AND require_history = false))" +
+				" AND has($5, school_province) AND is_science = true AND has($6, substring(major_code, 1, 4))" +
+				" AND major_name LIKE $7 AND min_rank_2024 BETWEEN $8 AND $9 AND has($10, school_level)" +
+				" AND toUInt32OrNull(tuition_fee) IS NOT NULL AND toUInt32OrZero(tuition_fee) <= $11",
+			args: append(baseArgs, []string{"物理", "化学", "地理"}, []string{"湖北"}, []string{"0701"}, "%数学%", int64(1), int64(9000), []string{"双一流"}, int64(6000)),
+		},
+	}
+
+	db := &ClickHouseDB{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := baseQuery()
+			if tt.modify != nil {
+				tt.modify(q)
+			}
+			sql, args := db.buildReportFilter(q).selectFrom("COUNT(*)").Build()
+
+			from, where, found := strings.Cut(sql, " WHERE ")
+			if !found {
+				t.Fatalf("no WHERE clause: %s", sql)
+			}
+			if tt.from == "" {
+				tt.from = "gaokao2025"
+			}
+			if !strings.Contains(from, tt.from) {
+				t.Errorf("FROM = %q, want it to contain %q", from, tt.from)
+			}
+			if where != tt.where {
+				t.Errorf("WHERE = %q\nwant   %q", where, tt.where)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v\nwant   %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestReportRowPageQuery(t *testing.T) {
+	sql, args := rowPageQuery((&ClickHouseDB{}).buildReportFilter(baseQuery()), 3, 10).Build()
+	if !strings.HasSuffix(sql, "WHERE "+baseWhere+" ORDER BY min_score_2024 DESC, school_code, major_group_code, major_code LIMIT $4 OFFSET $5") {
+		t.Errorf("unexpected SQL: %s", sql)
+	}
+	want := []interface{}{"湖北", "物理", []string{"本科批"}, int64(10), int64(20)}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %#v, want %#v", args, want)
+	}
+}
+
+func TestReportGroupPageQuery(t *testing.T) {
+	sql, args := groupPageQuery((&ClickHouseDB{}).buildReportFilter(baseQuery()), 2, 10).Build()
+	want := "SELECT concat(school_code, '|', major_group_code) AS group_key FROM gaokao2025 WHERE " + baseWhere +
+		" GROUP BY school_code, major_group_code ORDER BY max(min_score_2024) DESC, school_code, major_group_code LIMIT $4 OFFSET $5"
+	if sql != want {
+		t.Errorf("SQL = %s\nwant  %s", sql, want)
+	}
+	wantArgs := []interface{}{"湖北", "物理", []string{"本科批"}, int64(10), int64(10)}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+// 旧版报表的选科筛选和分页都在SQL中完成
+func TestLegacyReportPageQuery(t *testing.T) {
+	sql, args := rowPageQuery(legacyReportFilter(570, 620, "湖北", parseClassComb("123")), 3, 10).Build()
+	want := "FROM gaokao2025 WHERE min_score_2024 BETWEEN $1 AND $2 AND source_province = $3" +
+		" AND (arrayExists(c -> hasAll($4, c), subject_requirement)" +
+		" OR (empty(subject_requirement) AND require_politics = false AND require_history = false AND require_geography = false))" +
+		" ORDER BY min_score_2024 DESC, school_code, major_group_code, major_code LIMIT $5 OFFSET $6"
+	if !strings.HasSuffix(sql, want) {
+		t.Errorf("SQL = %s\nwant suffix %s", sql, want)
+	}
+	wantArgs := []interface{}{int64(570), int64(620), "湖北", []string{"物理", "化学", "生物"}, int64(10), int64(20)}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestParseClassComb(t *testing.T) {
+	tests := []struct {
+		classComb string
+		want      []string
+	}{
+		{"", nil},
+		{"789", nil},
+		{"1", []string{"物理"}},
+		{`"123"`, []string{"物理", "化学", "生物"}},
+		{"1' OR '2'='2", []string{"物理", "化学", "化学"}},
+	}
+	for _, tt := range tests {
+		if got := parseClassComb(tt.classComb); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseClassComb(%q) = %v, want %v", tt.classComb, got, tt.want)
+		}
+	}
+}
+
+// 表名不带数据库前缀，与迁移、导入一样使用连接的数据库（CLICKHOUSE_DATABASE）
+func TestQueriesUseConnectionDatabase(t *testing.T) {
+	db := &ClickHouseDB{}
+	history := baseQuery()
+	history.Year = 2023
+	groups := []models.MajorGroupRef{{CollegeCode: "10487", MajorGroupCode: "01"}}
+	queries := map[string]string{}
+	queries["report"], _ = db.buildReportFilter(baseQuery()).selectRows().Build()
+	queries["report history year"], _ = db.buildReportFilter(history).selectRows().Build()
+	queries["group majors"], _ = groupMajorsQuery("湖北", "物理", "本科批", groups).Build()
+	queries["school majors"], _ = schoolMajorsQuery("湖北", "物理", "本科批", "10487").Build()
+	queries["legacy report"], _ = legacyReportFilter(570, 620, "湖北", nil).selectRows().Build()
+	queries["admission history"], _ = admissionHistoryQuery("湖北", "物理", 2022, 2024, []string{"10487"}).Build()
+	for name, sql := range queries {
+		if strings.Contains(sql, "default.") {
+			t.Errorf("%s: SQL has a hard-coded database: %s", name, sql)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/querybuilder"
+)
+
+// 按取值精确匹配的字符串筛选字段
+type stringFilterColumn struct {
+	Column string
+	Filter models.StringFilter
+	Value  func(row *models.AdmissionHubeiWide) string
+}
+
+func stringFilterColumns(f *models.ReportFilters) []stringFilterColumn {
+	return []stringFilterColumn{
+		{"school_level", f.SchoolLevel, func(row *models.AdmissionHubeiWide) string { return row.SchoolLevel }},
+		{"school_ownership", f.SchoolOwnership, func(row *models.AdmissionHubeiWide) string { return row.SchoolOwnership }},
+		{"school_type", f.SchoolType, func(row *models.AdmissionHubeiWide) string { return row.SchoolType }},
+		{"school_province", f.SchoolProvince, func(row *models.AdmissionHubeiWide) string { return row.SchoolProvince }},
+		{"school_city", f.SchoolCity, func(row *models.AdmissionHubeiWide) string { return row.SchoolCity }},
+		{"education_level", f.EducationLevel, func(row *models.AdmissionHubeiWide) string { return row.EducationLevel }},
+	}
+}
+
+// 数值范围筛选字段，学费是字符串字段，按数字比较并排除学费为空或非数字（如"待定"）的专业
+type rangeFilterColumn struct {
+	Expr   string // 比较的表达式
+	Guard  string // 设置范围时附加的条件
+	Filter models.RangeFilter
+	Value  func(row *models.AdmissionHubeiWide) (int64, bool)
+}
+
+func rangeFilterColumns(f *models.ReportFilters) []rangeFilterColumn {
+	return []rangeFilterColumn{
+		{"toUInt32OrZero(tuition_fee)", "toUInt32OrNull(tuition_fee) IS NOT NULL", f.TuitionFee, func(row *models.AdmissionHubeiWide) (int64, bool) {
+			fee, err := strconv.ParseUint(row.TuitionFee, 10, 32)
+			return int64(fee), err == nil
+		}},
+		{"study_duration", "", f.StudyDuration, func(row *models.AdmissionHubeiWide) (int64, bool) {
+			return int64(row.StudyDuration), true
+		}},
+	}
+}
+
+// 将结构化筛选条件编译为参数化的WHERE条件。批次的 include 由调用方代替批次条件
+func compileFilters(f *models.ReportFilters) []querybuilder.Cond {
+	var conditions []querybuilder.Cond
+
+	// 院校标签是逗号分隔的多个标签，按包含匹配
+	var tagConditions []querybuilder.Cond
+	for _, tag := range f.SchoolTags.Include {
+		tagConditions = append(tagConditions, querybuilder.Expr("positionUTF8(school_tags, ?) > 0", tag))
+	}
+	if len(tagConditions) > 0 {
+		conditions = append(conditions, querybuilder.Or(tagConditions...))
+	}
+	for _, tag := range f.SchoolTags.Exclude {
+		conditions = append(conditions, querybuilder.Expr("positionUTF8(school_tags, ?) = 0", tag))
+	}
+
+	for _, c := range stringFilterColumns(f) {
+		if len(c.Filter.Include) > 0 {
+			conditions = append(conditions, querybuilder.In(c.Column, c.Filter.Include))
+		}
+		if len(c.Filter.Exclude) > 0 {
+			conditions = append(conditions, querybuilder.NotIn(c.Column, c.Filter.Exclude))
+		}
+	}
+	if len(f.AdmissionBatch.Exclude) > 0 {
+		conditions = append(conditions, querybuilder.NotIn("admission_batch", f.AdmissionBatch.Exclude))
+	}
+
+	for _, c := range rangeFilterColumns(f) {
+		if c.Filter.Min == nil && c.Filter.Max == nil {
+			continue
+		}
+		if c.Guard != "" {
+			conditions = append(conditions, querybuilder.Expr(c.Guard))
+		}
+		if c.Filter.Min != nil {
+			conditions = append(conditions, querybuilder.Expr(c.Expr+" >= ?", *c.Filter.Min))
+		}
+		if c.Filter.Max != nil {
+			conditions = append(conditions, querybuilder.Expr(c.Expr+" <= ?", *c.Filter.Max))
+		}
+	}
+
+	if f.IsNewMajor != nil {
+		conditions = append(conditions, querybuilder.Eq("is_new_major", *f.IsNewMajor))
+	}
+	return conditions
+}
+
+// 结构化筛选，与 compileFilters 生成的条件一致
+func matchFilters(row *models.AdmissionHubeiWide, f *models.ReportFilters) bool {
+	if len(f.SchoolTags.Include) > 0 {
+		found := false
+		for _, tag := range f.SchoolTags.Include {
+			if strings.Contains(row.SchoolTags, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range f.SchoolTags.Exclude {
+		if strings.Contains(row.SchoolTags, tag) {
+			return false
+		}
+	}
+
+	for _, c := range stringFilterColumns(f) {
+		value := c.Value(row)
+		if len(c.Filter.Include) > 0 && !containsString(c.Filter.Include, value) {
+			return false
+		}
+		if containsString(c.Filter.Exclude, value) {
+			return false
+		}
+	}
+	if containsString(f.AdmissionBatch.Exclude, row.AdmissionBatch) {
+		return false
+	}
+
+	for _, c := range rangeFilterColumns(f) {
+		if c.Filter.Min == nil && c.Filter.Max == nil {
+			continue
+		}
+		value, ok := c.Value(row)
+		if !ok || (c.Filter.Min != nil && value < *c.Filter.Min) || (c.Filter.Max != nil && value > *c.Filter.Max) {
+			return false
+		}
+	}
+
+	if f.IsNewMajor != nil && row.IsNewMajor != *f.IsNewMajor {
+		return false
+	}
+	return true
+}
+
+// 报表查询的批次：批次筛选的 include 不为空时代替 batch 参数
+func reportBatches(q *models.ReportQuery) []string {
+	if len(q.Filters.AdmissionBatch.Include) > 0 {
+		return q.Filters.AdmissionBatch.Include
+	}
+	return []string{q.Batch}
+}
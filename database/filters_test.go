@@ -0,0 +1,42 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 学费范围筛选在两种存储上都要排除学费为空或非数字的专业
+func TestTuitionFilterNonNumeric(t *testing.T) {
+	f := &models.ReportFilters{TuitionFee: models.RangeFilter{Max: int64Ptr(10000)}}
+	tests := []struct {
+		fee  string
+		want bool
+	}{
+		{"5000", true},
+		{"10000", true},
+		{"12000", false},
+		{"", false},
+		{"待定", false},
+		{"5000元", false},
+		{"-1", false},
+	}
+
+	// ClickHouse：toUInt32OrZero 把非数字转为0，必须由 toUInt32OrNull 排除
+	q := baseQuery()
+	q.Filters = *f
+	sql, _ := (&ClickHouseDB{}).buildReportFilter(q).selectRows().Build()
+	if !strings.Contains(sql, "toUInt32OrNull(tuition_fee) IS NOT NULL AND toUInt32OrZero(tuition_fee) <= ") {
+		t.Errorf("SQL does not exclude non-numeric tuition_fee: %s", sql)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fee, func(t *testing.T) {
+			row := &models.AdmissionHubeiWide{TuitionFee: tt.fee}
+			if got := matchFilters(row, f); got != tt.want {
+				t.Errorf("matchFilters(tuition_fee=%q) = %v, want %v", tt.fee, got, tt.want)
+			}
+		})
+	}
+}
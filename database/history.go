@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"log"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/querybuilder"
+)
+
+// 查询指定院校在年份区间内的历年录取数据（包括专业组和专业两个层级）
+func (db *ClickHouseDB) GetAdmissionHistory(province, subjectCategory string, fromYear, toYear int, schoolCodes []string) ([]models.AdmissionHistory, error) {
+	if len(schoolCodes) == 0 {
+		return nil, nil
+	}
+
+	query, args := admissionHistoryQuery(province, subjectCategory, fromYear, toYear, schoolCodes).Build()
+	rows, err := db.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.AdmissionHistory
+	for rows.Next() {
+		var item models.AdmissionHistory
+		if err := rows.ScanStruct(&item); err != nil {
+			log.Printf("扫描历年录取数据错误: %v", err)
+			continue
+		}
+		history = append(history, item)
+	}
+	return history, nil
+}
+
+func admissionHistoryQuery(province, subjectCategory string, fromYear, toYear int, schoolCodes []string) *querybuilder.SelectBuilder {
+	return querybuilder.Select("year", "source_province", "subject_category", "admission_batch", "school_code", "major_group_code", "major_code",
+		"min_score", "min_rank", "avg_score", "max_score", "enrollment_plan", "admission_num").
+		From("admission_history FINAL").
+		Where(querybuilder.Eq("source_province", province),
+			querybuilder.Eq("subject_category", subjectCategory),
+			querybuilder.Between("year", fromYear, toYear),
+			querybuilder.In("school_code", schoolCodes)).
+		OrderBy("year DESC")
+}
+
+// 录取分数线的数据来源：参考年份直接使用gaokao2025宽表的 *_2024 字段，
+// 其他年份关联admission_history中该年的专业组数据
+func cutoffSource(year int) (from querybuilder.Cond, scoreColumn, rankColumn string) {
+	if year == models.ReferenceAdmissionYear {
+		return querybuilder.Expr("gaokao2025"), "min_score_2024", "min_rank_2024"
+	}
+
+	from = querybuilder.Expr(`gaokao2025 AS g
+		INNER JOIN (
+			SELECT source_province AS h_province, subject_category AS h_category,
+				   school_code AS h_school_code, major_group_code AS h_group_code,
+				   min_score AS h_min_score, min_rank AS h_min_rank
+			FROM admission_history FINAL
+			WHERE year = ? AND major_code = ''
+		) AS h
+		ON g.source_province = h.h_province AND g.subject_category = h.h_category
+		AND g.school_code = h.h_school_code AND g.major_group_code = h.h_group_code`, year)
+	return from, "h_min_score", "h_min_rank"
+}
@@ -0,0 +1,568 @@
+package database
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/scorerank"
+	"gaokao-zhiyuan/subjectreq"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+// MemoryDB 基于内存快照的录取数据存储
+// 从gaokao2025表导出的JSON/CSV快照加载全部数据，查询在内存中完成，
+// 用于离线运行和测试，查询语义与ClickHouseDB保持一致
+type MemoryDB struct {
+	rows    []models.AdmissionHubeiWide
+	history []models.AdmissionHistory
+	// 历年专业组录取分数线索引，key见 groupCutoffKey
+	groupCutoffs map[string]models.AdmissionHistory
+	ranks        *scorerank.Registry
+}
+
+// NewMemoryDB 从快照文件创建内存存储，根据扩展名识别JSON或CSV格式。
+// historyPath 为可选的admission_history快照，参考年份的数据总是从gaokao2025宽表推导
+func NewMemoryDB(path, historyPath string, ranks *scorerank.Registry) (*MemoryDB, error) {
+	var rows []models.AdmissionHubeiWide
+	if err := loadSnapshot(path, &rows); err != nil {
+		return nil, err
+	}
+	log.Printf("已从快照 %s 加载 %d 条录取数据", path, len(rows))
+
+	var history []models.AdmissionHistory
+	if historyPath != "" {
+		if err := loadSnapshot(historyPath, &history); err != nil {
+			return nil, err
+		}
+		log.Printf("已从快照 %s 加载 %d 条历年录取数据", historyPath, len(history))
+	}
+
+	return NewMemoryDBFromRows(rows, history, ranks), nil
+}
+
+// NewMemoryDBFromRows 使用给定的录取数据和历年录取数据创建内存存储
+func NewMemoryDBFromRows(rows []models.AdmissionHubeiWide, history []models.AdmissionHistory, ranks *scorerank.Registry) *MemoryDB {
+	db := &MemoryDB{
+		rows:         rows,
+		groupCutoffs: make(map[string]models.AdmissionHistory),
+		ranks:        ranks,
+	}
+
+	// 没有结构化选科要求的旧快照，按原始选科限制解析，无法识别时沿用 require_* 字段
+	for i := range rows {
+		if len(rows[i].SubjectRequirement) > 0 {
+			continue
+		}
+		if r, err := subjectreq.Parse(rows[i].SubjectRequirementRaw); err == nil {
+			rows[i].SubjectRequirement = r.DNF()
+		}
+	}
+
+	// 与ClickHouse回填逻辑一致：参考年份数据来自宽表，快照中的同年数据被忽略
+	db.history = deriveReferenceHistory(rows)
+	for _, h := range history {
+		if int(h.Year) != models.ReferenceAdmissionYear {
+			db.history = append(db.history, h)
+		}
+	}
+
+	for _, h := range db.history {
+		if h.MajorCode == "" {
+			db.groupCutoffs[groupCutoffKey(int(h.Year), h.SourceProvince, h.SubjectCategory, h.SchoolCode, h.MajorGroupCode)] = h
+		}
+	}
+	return db
+}
+
+// 加载快照文件到 out 指向的切片，根据扩展名识别JSON或CSV格式
+func loadSnapshot(path string, out interface{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开快照文件失败: %v", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = loadJSONSnapshot(file, out)
+	case ".csv":
+		err = loadCSVSnapshot(file, out)
+	default:
+		return fmt.Errorf("不支持的快照文件格式: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("解析快照文件 %s 失败: %v", path, err)
+	}
+	return nil
+}
+
+// 加载JSON快照，支持数组或 {"data": [...]} 两种格式
+func loadJSONSnapshot(r io.Reader, out interface{}) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(content, out); err == nil {
+		return nil
+	}
+
+	wrapped := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	return json.Unmarshal(content, &wrapped)
+}
+
+// 加载CSV快照，表头使用ClickHouse表的字段名（即模型的ch标签）
+func loadCSVSnapshot(r io.Reader, out interface{}) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	// 字段名 -> 结构体字段下标
+	slice := reflect.ValueOf(out).Elem()
+	modelType := slice.Type().Elem()
+	fieldIndex := make(map[string]int)
+	for i := 0; i < modelType.NumField(); i++ {
+		if tag := modelType.Field(i).Tag.Get("ch"); tag != "" {
+			fieldIndex[tag] = i
+		}
+	}
+
+	columns := make([]int, len(header))
+	for i, name := range header {
+		idx, ok := fieldIndex[strings.TrimSpace(name)]
+		if !ok {
+			log.Printf("快照CSV中的未知字段 %s 将被忽略", name)
+			idx = -1
+		}
+		columns[i] = idx
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return fmt.Errorf("第%d行: %v", line, err)
+		}
+
+		value := reflect.New(modelType).Elem()
+		for i, cell := range record {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+			if err := setSnapshotField(value.Field(columns[i]), cell); err != nil {
+				return fmt.Errorf("第%d行字段 %s: %v", line, header[i], err)
+			}
+		}
+		slice.Set(reflect.Append(slice, value))
+	}
+	return nil
+}
+
+// 按字段类型解析CSV单元格
+func setSnapshotField(field reflect.Value, cell string) error {
+	cell = strings.TrimSpace(cell)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(cell)
+	case reflect.Bool:
+		if cell == "" {
+			return nil
+		}
+		v, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Slice:
+		// 数组字段为JSON，也接受ClickHouse导出的单引号写法，如 [['物理','化学']]
+		if cell == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(strings.ReplaceAll(cell, "'", `"`)), field.Addr().Interface())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if cell == "" {
+			return nil
+		}
+		v, err := strconv.ParseUint(cell, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", field.Kind())
+	}
+	return nil
+}
+
+func (db *MemoryDB) Close() error {
+	return nil
+}
+
+// 筛选录取数据，并将 MinScore2024/MinRank2024 替换为参考年份的专业组录取分数线，
+// 与 cutoffSource 一致：非宽表年份时只保留该年有录取数据的专业组
+func (db *MemoryDB) cutoffRows(year int, match func(row *models.AdmissionHubeiWide) bool) []models.AdmissionHubeiWide {
+	var rows []models.AdmissionHubeiWide
+	for i := range db.rows {
+		row := db.rows[i]
+		if !match(&row) {
+			continue
+		}
+		if year != models.ReferenceAdmissionYear {
+			cutoff, ok := db.groupCutoffs[groupCutoffKey(year, row.SourceProvince, row.SubjectCategory, row.SchoolCode, row.MajorGroupCode)]
+			if !ok {
+				continue
+			}
+			row.MinScore2024 = cutoff.MinScore
+			row.MinRank2024 = cutoff.MinRank
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func groupCutoffKey(year int, province, subjectCategory, schoolCode, majorGroupCode string) string {
+	return fmt.Sprintf("%d|%s|%s|%s", year, province, subjectCategory, historyKey(schoolCode, majorGroupCode, ""))
+}
+
+// 按报表查询参数筛选录取数据 - 语义同 ClickHouseDB.buildReportFilter
+func (db *MemoryDB) filterRows(q *models.ReportQuery) []models.AdmissionHubeiWide {
+	candidates := db.cutoffRows(q.Year, func(row *models.AdmissionHubeiWide) bool {
+		return row.SourceProvince == q.Province && row.SubjectCategory == q.ClassFirstChoice
+	})
+
+	var matched []models.AdmissionHubeiWide
+	for i := range candidates {
+		row := &candidates[i]
+		if !containsString(reportBatches(q), row.AdmissionBatch) {
+			continue
+		}
+		if !SubjectEligible(row, q.ClassFirstChoice, q.ClassOptionalChoice) {
+			continue
+		}
+		if len(q.CollegeLocation) > 0 && !containsString(q.CollegeLocation, row.SchoolProvince) {
+			continue
+		}
+		if !matchInterests(row, q.InterestFlags) {
+			continue
+		}
+		if len(q.MajorCategories) > 0 {
+			if code, ok := taxonomy.CategoryCode(row.MajorCode); !ok || !containsString(q.MajorCategories, code) {
+				continue
+			}
+		}
+		if q.FuzzySubjectCategory != "" && !strings.Contains(row.MajorName, q.FuzzySubjectCategory) {
+			continue
+		}
+		if q.MajorCode != "" && row.MajorCode != q.MajorCode {
+			continue
+		}
+		if q.MaxCutoffRank > 0 && (int64(row.MinRank2024) < q.MinCutoffRank || int64(row.MinRank2024) > q.MaxCutoffRank) {
+			continue
+		}
+		if q.MaxCutoffScore > 0 && (int64(row.MinScore2024) < q.MinCutoffScore || int64(row.MinScore2024) > q.MaxCutoffScore) {
+			continue
+		}
+		if !matchFilters(row, &q.Filters) {
+			continue
+		}
+		matched = append(matched, *row)
+	}
+	return matched
+}
+
+// 新的报表查询接口 - 语义同 ClickHouseDB.GetReportDataNew
+func (db *MemoryDB) GetReportDataNew(q *models.ReportQuery) (*models.Response, error) {
+	matched := db.filterRows(q)
+	sortByScore(matched)
+
+	var totalCount int64
+	var page []models.AdmissionHubeiWide
+	offset := (q.Page - 1) * q.PageSize
+	if q.GroupByMajorGroup {
+		// 按专业组分页，专业组按组内最高的专业组录取分排序
+		var keys []string
+		groups := make(map[string][]models.AdmissionHubeiWide)
+		for _, row := range matched {
+			key := historyKey(row.SchoolCode, row.MajorGroupCode, "")
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], row)
+		}
+		totalCount = int64(len(keys))
+		if offset < totalCount {
+			for _, key := range keys[offset:minInt64(offset+q.PageSize, totalCount)] {
+				// 组内专业按专业最低分降序、专业代码升序
+				rows := groups[key]
+				sort.SliceStable(rows, func(i, j int) bool {
+					if rows[i].MajorMinScore2024 != rows[j].MajorMinScore2024 {
+						return rows[i].MajorMinScore2024 > rows[j].MajorMinScore2024
+					}
+					return rows[i].MajorCode < rows[j].MajorCode
+				})
+				page = append(page, rows...)
+			}
+		}
+	} else {
+		totalCount = int64(len(matched))
+		if offset < totalCount {
+			page = matched[offset:minInt64(offset+q.PageSize, totalCount)]
+		}
+	}
+
+	list := make([]models.List, 0, len(page))
+	for i := range page {
+		list = append(list, buildReportItem(db.ranks, &page[i], q.Province, q.ClassFirstChoice))
+	}
+
+	// 附加历年录取数据
+	fromYear, toYear := historyYearRange(q.Year, q.HistoryYears)
+	history, _ := db.GetAdmissionHistory(q.Province, q.ClassFirstChoice, fromYear, toYear, distinctSchoolCodes(page))
+	attachHistory(list, page, history)
+	attachEquivalentScores(db.ranks, list, q)
+
+	return buildReport(q, list, page, totalCount), nil
+}
+
+// 读取全部符合条件的报表行 - 语义同 ClickHouseDB.StreamReport
+func (db *MemoryDB) StreamReport(ctx context.Context, q *models.ReportQuery, fn func(batch []models.List) error) error {
+	matched := db.filterRows(q)
+	sortByScore(matched)
+
+	batch := make([]models.List, 0, StreamBatchSize)
+	for i := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch = append(batch, buildReportItem(db.ranks, &matched[i], q.Province, q.ClassFirstChoice))
+		if len(batch) == StreamBatchSize {
+			if err := flushStreamBatch(db.ranks, batch, q, fn); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return flushStreamBatch(db.ranks, batch, q, fn)
+}
+
+// 查询所有符合条件的录取数据 - 语义同 ClickHouseDB.ListAdmissions
+func (db *MemoryDB) ListAdmissions(q *models.ReportQuery) ([]models.AdmissionHubeiWide, error) {
+	matched := db.filterRows(q)
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := &matched[i], &matched[j]
+		if a.MinRank2024 != b.MinRank2024 {
+			return a.MinRank2024 < b.MinRank2024
+		}
+		return lessByCode(a, b)
+	})
+	return matched, nil
+}
+
+// 按专业组录取分降序排列，同分按院校代码、专业组代码、专业代码，与 ClickHouseDB 的分页顺序一致
+func sortByScore(rows []models.AdmissionHubeiWide) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		if a.MinScore2024 != b.MinScore2024 {
+			return a.MinScore2024 > b.MinScore2024
+		}
+		return lessByCode(a, b)
+	})
+}
+
+// 按院校代码、专业组代码、专业代码比较，同 codeOrder
+func lessByCode(a, b *models.AdmissionHubeiWide) bool {
+	if a.SchoolCode != b.SchoolCode {
+		return a.SchoolCode < b.SchoolCode
+	}
+	if a.MajorGroupCode != b.MajorGroupCode {
+		return a.MajorGroupCode < b.MajorGroupCode
+	}
+	return a.MajorCode < b.MajorCode
+}
+
+// 查询院校专业组内的全部专业 - 语义同 ClickHouseDB.GetGroupMajors
+func (db *MemoryDB) GetGroupMajors(province, subjectCategory, batch string, groups []models.MajorGroupRef) ([]models.AdmissionHubeiWide, error) {
+	keys := groupKeys(groups)
+	var rows []models.AdmissionHubeiWide
+	for _, row := range db.rows {
+		if row.SourceProvince == province && row.SubjectCategory == subjectCategory && row.AdmissionBatch == batch &&
+			containsString(keys, row.SchoolCode+"|"+row.MajorGroupCode) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		if a.SchoolCode != b.SchoolCode {
+			return a.SchoolCode < b.SchoolCode
+		}
+		if a.MajorGroupCode != b.MajorGroupCode {
+			return a.MajorGroupCode < b.MajorGroupCode
+		}
+		return a.MajorMinScore2024 < b.MajorMinScore2024
+	})
+	return rows, nil
+}
+
+// 查询院校在某科类、批次的全部专业 - 语义同 ClickHouseDB.GetSchoolMajors
+func (db *MemoryDB) GetSchoolMajors(province, subjectCategory, batch, schoolCode string) ([]models.AdmissionHubeiWide, error) {
+	var rows []models.AdmissionHubeiWide
+	for _, row := range db.rows {
+		if row.SourceProvince == province && row.SubjectCategory == subjectCategory && row.AdmissionBatch == batch && row.SchoolCode == schoolCode {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := &rows[i], &rows[j]
+		if a.MajorGroupCode != b.MajorGroupCode {
+			return a.MajorGroupCode < b.MajorGroupCode
+		}
+		return a.MajorCode < b.MajorCode
+	})
+	return rows, nil
+}
+
+// 查询院校在年份区间内的历年录取数据 - 语义同 ClickHouseDB.GetAdmissionHistory
+func (db *MemoryDB) GetAdmissionHistory(province, subjectCategory string, fromYear, toYear int, schoolCodes []string) ([]models.AdmissionHistory, error) {
+	var history []models.AdmissionHistory
+	for _, h := range db.history {
+		if h.SourceProvince != province || h.SubjectCategory != subjectCategory {
+			continue
+		}
+		if int(h.Year) < fromYear || int(h.Year) > toYear || !containsString(schoolCodes, h.SchoolCode) {
+			continue
+		}
+		history = append(history, h)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Year > history[j].Year
+	})
+	return history, nil
+}
+
+// 获取数据记录数
+func (db *MemoryDB) GetDataCount() (int64, error) {
+	return int64(len(db.rows)), nil
+}
+
+// 查询当前最大的记录ID - 语义同 ClickHouseDB.MaxAdmissionID
+func (db *MemoryDB) MaxAdmissionID() (uint32, error) {
+	var maxID uint32
+	for i := range db.rows {
+		if db.rows[i].ID > maxID {
+			maxID = db.rows[i].ID
+		}
+	}
+	return maxID, nil
+}
+
+// 查询省份已有录取数据的院校、专业组和专业代码 - 语义同 ClickHouseDB.ListAdmissionKeys
+func (db *MemoryDB) ListAdmissionKeys(provinces []string) ([]models.AdmissionHubeiWide, error) {
+	var keys []models.AdmissionHubeiWide
+	seen := make(map[string]bool)
+	for i := range db.rows {
+		row := &db.rows[i]
+		key := row.SourceProvince + "|" + row.SchoolCode + "|" + row.MajorGroupCode + "|" + row.MajorCode
+		if !containsString(provinces, row.SourceProvince) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, models.AdmissionHubeiWide{
+			SourceProvince: row.SourceProvince,
+			SchoolCode:     row.SchoolCode,
+			MajorGroupCode: row.MajorGroupCode,
+			MajorCode:      row.MajorCode,
+		})
+	}
+	return keys, nil
+}
+
+// RowRequirement 专业的选科要求：优先使用导入时解析的 subject_requirement，
+// 没有解析结果的旧数据按 require_* 字段，要求的科目都要选
+func RowRequirement(row *models.AdmissionHubeiWide) *subjectreq.Requirement {
+	if len(row.SubjectRequirement) > 0 {
+		return subjectreq.FromDNF(row.SubjectRequirement)
+	}
+
+	required := map[string]bool{
+		"require_physics":   row.RequirePhysics,
+		"require_chemistry": row.RequireChemistry,
+		"require_biology":   row.RequireBiology,
+		"require_politics":  row.RequirePolitics,
+		"require_history":   row.RequireHistory,
+		"require_geography": row.RequireGeography,
+	}
+	var subjects []*subjectreq.Requirement
+	for _, item := range subjectFields {
+		if required[item.Field] {
+			subjects = append(subjects, subjectreq.Subject(item.Subject))
+		}
+	}
+	return subjectreq.All(subjects...)
+}
+
+// SubjectEligible 判断考生选科是否满足专业的选科要求，与 buildSubjectConditions 生成的条件一致。
+// 首选科目也视为已选；没有再选科目时不做限制
+func SubjectEligible(row *models.AdmissionHubeiWide, classFirstChoice string, classOptionalChoice []string) bool {
+	if len(classOptionalChoice) == 0 {
+		return true
+	}
+	return RowRequirement(row).Eligible(append([]string{classFirstChoice}, classOptionalChoice...))
+}
+
+// 专业兴趣筛选，与 buildInterestConditions 生成的条件一致
+func matchInterests(row *models.AdmissionHubeiWide, flags []string) bool {
+	filtered := false
+	for _, flag := range flags {
+		if !taxonomy.IsFlagColumn(flag) {
+			continue
+		}
+		filtered = true
+		if taxonomy.FlagValue(row, flag) {
+			return true
+		}
+	}
+	return !filtered
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/scorerank"
+)
+
+func pageRow(school, group, major string, score, majorScore uint16) models.AdmissionHubeiWide {
+	return models.AdmissionHubeiWide{
+		SourceProvince:    "湖北",
+		SubjectCategory:   "物理",
+		AdmissionBatch:    "本科批",
+		SchoolCode:        school,
+		MajorGroupCode:    group,
+		MajorCode:         major,
+		MajorName:         major,
+		MinScore2024:      score,
+		MinRank2024:       uint32(10000 - int(score)),
+		MajorMinScore2024: majorScore,
+	}
+}
+
+// 同分的专业按院校代码、专业组代码、专业代码排列，与 ClickHouseDB 的 ORDER BY 一致，翻页时不重复不遗漏
+func TestMemoryReportPageOrder(t *testing.T) {
+	rows := []models.AdmissionHubeiWide{
+		pageRow("10500", "01", "080902", 600, 600),
+		pageRow("10487", "02", "080901", 600, 600),
+		pageRow("10487", "01", "080902", 600, 601),
+		pageRow("10487", "01", "080901", 600, 601),
+		pageRow("10500", "01", "080901", 600, 605),
+		pageRow("10001", "01", "080901", 590, 590),
+	}
+	db := NewMemoryDBFromRows(rows, nil, scorerank.NewRegistry())
+
+	page := func(groupBy bool, n, size int64) []string {
+		q := baseQuery()
+		q.Page, q.PageSize, q.GroupByMajorGroup = n, size, groupBy
+		resp, err := db.GetReportDataNew(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, item := range resp.Data.List {
+			keys = append(keys, *item.CollegeCode+"|"+*item.SpecialInterestGroupCode+"|"+item.ProfessionalName)
+		}
+		for _, g := range resp.Data.Groups {
+			for _, m := range g.Majors {
+				keys = append(keys, g.CollegeCode+"|"+g.MajorGroupCode+"|"+m.ProfessionalName)
+			}
+		}
+		return keys
+	}
+
+	var got []string
+	for n := int64(1); n <= 3; n++ {
+		got = append(got, page(false, n, 2)...)
+	}
+	want := []string{
+		"10487|01|080901", "10487|01|080902", "10487|02|080901",
+		"10500|01|080901", "10500|01|080902", "10001|01|080901",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v\nwant   %v", got, want)
+	}
+
+	// 按专业组分页：专业组同分按代码排列，组内专业按专业最低分降序、专业代码升序
+	got = append(page(true, 1, 2), page(true, 2, 2)...)
+	want = []string{
+		"10487|01|080901", "10487|01|080902", "10487|02|080901",
+		"10500|01|080901", "10500|01|080902", "10001|01|080901",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v\nwant     %v", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gaokao-zhiyuan/database/migrations"
+)
+
+// 迁移文件名：{版本号}_{名称}.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// Migration 一个版本的表结构迁移
+type Migration struct {
+	Version    uint32
+	Name       string
+	Statements []string
+}
+
+// MigrationState 迁移的执行状态
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// 读取内置的迁移脚本，按版本号排序
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Migration
+	seen := make(map[uint32]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", entry.Name())
+		}
+		version, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("迁移版本号错误: %s", entry.Name())
+		}
+		if other, ok := seen[uint32(version)]; ok {
+			return nil, fmt.Errorf("迁移版本号重复: %s 与 %s", entry.Name(), other)
+		}
+		seen[uint32(version)] = entry.Name()
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		statements := splitStatements(string(content))
+		if len(statements) == 0 {
+			return nil, fmt.Errorf("迁移文件为空: %s", entry.Name())
+		}
+		list = append(list, Migration{Version: uint32(version), Name: m[2], Statements: statements})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// 按行尾分号拆分SQL语句，忽略 -- 开头的注释行
+func splitStatements(content string) []string {
+	var statements []string
+	var current []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			statements = append(statements, statement)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+	return statements
+}
+
+// 创建迁移记录表
+func (db *ClickHouseDB) createMigrationTable() error {
+	query := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    UInt32,
+		name       String,
+		applied_at DateTime DEFAULT now()
+	) ENGINE = MergeTree()
+	ORDER BY version
+	`
+	return db.conn.Exec(context.Background(), query)
+}
+
+// 查询已执行的迁移版本及执行时间
+func (db *ClickHouseDB) appliedMigrations() (map[uint32]time.Time, error) {
+	rows, err := db.conn.Query(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint32]time.Time)
+	for rows.Next() {
+		var version uint32
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// Migrate 按版本号依次执行未执行过的迁移，返回本次执行的迁移。
+// ClickHouse不支持DDL事务，迁移中的语句需保证可重复执行（IF NOT EXISTS等），
+// 失败后修复问题重新执行即可
+func (db *ClickHouseDB) Migrate() ([]Migration, error) {
+	states, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, state := range states {
+		if state.Applied {
+			continue
+		}
+		for i, statement := range state.Statements {
+			if err := db.conn.Exec(context.Background(), statement); err != nil {
+				return done, fmt.Errorf("执行迁移 %04d_%s 第%d条语句失败: %v", state.Version, state.Name, i+1, err)
+			}
+		}
+		if err := db.conn.Exec(context.Background(), "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", state.Version, state.Name); err != nil {
+			return done, fmt.Errorf("记录迁移 %04d_%s 失败: %v", state.Version, state.Name, err)
+		}
+		log.Printf("已执行迁移 %04d_%s", state.Version, state.Name)
+		done = append(done, state.Migration)
+	}
+	return done, nil
+}
+
+// MigrationStatus 返回所有内置迁移及其执行状态
+func (db *ClickHouseDB) MigrationStatus() ([]MigrationState, error) {
+	list, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移文件失败: %v", err)
+	}
+	if err := db.createMigrationTable(); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %v", err)
+	}
+
+	states := make([]MigrationState, len(list))
+	for i, m := range list {
+		appliedAt, ok := applied[m.Version]
+		states[i] = MigrationState{Migration: m, Applied: ok, AppliedAt: appliedAt}
+	}
+	return states, nil
+}
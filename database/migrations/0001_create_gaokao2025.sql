@@ -0,0 +1,44 @@
+-- 录取数据宽表
+CREATE TABLE IF NOT EXISTS gaokao2025 (
+	id                      UInt32,
+	school_code             String,
+	school_name             String,
+	major_code              String,
+	major_name              String,
+	major_group_code        String,
+	source_province         LowCardinality(String),
+	school_province         String,
+	school_city             String,
+	admission_batch         LowCardinality(String),
+	subject_category        LowCardinality(String),
+	require_physics         Bool,
+	require_chemistry       Bool,
+	require_biology         Bool,
+	require_politics        Bool,
+	require_history         Bool,
+	require_geography       Bool,
+	subject_requirement_raw String,
+	school_type             String,
+	school_ownership        Enum8('公办' = 1, '内地与港澳台合作办学' = 2, '中外合作办学' = 3, '民办' = 4, '境外高校独立办学' = 5),
+	school_authority        String,
+	school_level            String,
+	school_tags             String,
+	education_level         Enum8('本科' = 1, '职业本科' = 2, '专科' = 3),
+	major_description       String,
+	study_duration          UInt8,
+	tuition_fee             String,
+	is_new_major            Bool,
+	min_score_2024          UInt16,
+	min_rank_2024           UInt32,
+	major_min_score_2024    UInt16,
+	enrollment_plan_2024    UInt16,
+	is_science              Bool,
+	is_engineering          Bool,
+	is_medical              Bool,
+	is_economics_mgmt_law   Bool,
+	is_liberal_arts         Bool,
+	is_design_arts          Bool,
+	is_language             Bool
+) ENGINE = MergeTree()
+ORDER BY (id, school_code, major_code)
+SETTINGS index_granularity = 8192;
@@ -0,0 +1,4 @@
+-- 早期的表中省份、批次、科类为单省份的枚举类型，升级为字符串以支持多省份
+ALTER TABLE gaokao2025 MODIFY COLUMN source_province LowCardinality(String);
+ALTER TABLE gaokao2025 MODIFY COLUMN admission_batch LowCardinality(String);
+ALTER TABLE gaokao2025 MODIFY COLUMN subject_category LowCardinality(String);
@@ -0,0 +1,35 @@
+-- 历年录取数据长表，按年份存储专业组（major_code为空）和专业的录取分数、位次
+CREATE TABLE IF NOT EXISTS admission_history (
+	year                    UInt16,
+	source_province         LowCardinality(String),
+	subject_category        LowCardinality(String),
+	admission_batch         LowCardinality(String),
+	school_code             String,
+	major_group_code        String,
+	major_code              String,
+	min_score               UInt16,
+	min_rank                UInt32,
+	avg_score               UInt16,
+	max_score               UInt16,
+	enrollment_plan         UInt16,
+	admission_num           UInt16
+) ENGINE = ReplacingMergeTree()
+ORDER BY (source_province, subject_category, year, school_code, major_group_code, major_code)
+SETTINGS index_granularity = 8192;
+
+-- 从gaokao2025宽表的 *_2024 字段回填2024年专业组数据
+INSERT INTO admission_history (year, source_province, subject_category, admission_batch, school_code, major_group_code, major_code,
+	min_score, min_rank, enrollment_plan)
+SELECT 2024, source_province, subject_category, any(admission_batch), school_code, major_group_code, '',
+	any(min_score_2024), any(min_rank_2024), sum(enrollment_plan_2024)
+FROM gaokao2025
+WHERE min_score_2024 > 0
+GROUP BY source_province, subject_category, school_code, major_group_code;
+
+-- 回填2024年专业数据
+INSERT INTO admission_history (year, source_province, subject_category, admission_batch, school_code, major_group_code, major_code,
+	min_score, enrollment_plan)
+SELECT 2024, source_province, subject_category, admission_batch, school_code, major_group_code, major_code,
+	major_min_score_2024, enrollment_plan_2024
+FROM gaokao2025
+WHERE major_min_score_2024 > 0;
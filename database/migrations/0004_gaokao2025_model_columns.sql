@@ -0,0 +1,17 @@
+-- 补齐模型中已有、建表语句中缺失的字段
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS enrollment_plan UInt16;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_id String;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS enrollment_type LowCardinality(String);
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS enrollment_plan_year UInt16;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_category LowCardinality(String);
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS admission_num_2024 UInt16;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_min_rank_2024 UInt32;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_avg_score_2024 UInt16;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_avg_rank_2024 UInt32;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_max_score_2024 UInt16;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_max_rank_2024 UInt32;
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS major_admission_num_2024 UInt16;
+
+-- 分数、位次排序索引
+ALTER TABLE gaokao2025 ADD INDEX IF NOT EXISTS idx_min_score_2024 min_score_2024 TYPE minmax GRANULARITY 1;
+ALTER TABLE gaokao2025 ADD INDEX IF NOT EXISTS idx_min_rank_2024 min_rank_2024 TYPE minmax GRANULARITY 1;
@@ -0,0 +1,10 @@
+-- 结构化选科要求，由 subject_requirement_raw 解析得到的析取范式
+ALTER TABLE gaokao2025 ADD COLUMN IF NOT EXISTS subject_requirement Array(Array(String));
+
+-- 回填已有数据：按 require_* 字段生成只有一组科目的选科要求，都为false的保持空数组（不限）。
+-- 多选一的要求需要重新导入原始选科限制才能解析
+ALTER TABLE gaokao2025 UPDATE subject_requirement = [arrayFilter(s -> s != '', [
+	if(require_physics, '物理', ''), if(require_chemistry, '化学', ''), if(require_biology, '生物', ''),
+	if(require_politics, '政治', ''), if(require_history, '历史', ''), if(require_geography, '地理', '')])]
+WHERE empty(subject_requirement)
+	AND (require_physics OR require_chemistry OR require_biology OR require_politics OR require_history OR require_geography);
@@ -0,0 +1,8 @@
+// Package migrations 包含按版本号编号的ClickHouse迁移脚本，文件名格式为 {版本号}_{名称}.sql。
+// 已发布的迁移不能修改，表结构变更需要新增迁移，并同步更新 models 中的 chtype 标签
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,404 @@
+package database
+
+import (
+	"sort"
+	"strconv"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/scorerank"
+)
+
+// 选考科目与选科要求字段的对应关系
+var subjectFields = []struct {
+	Subject string
+	Field   string
+}{
+	{"物理", "require_physics"},
+	{"化学", "require_chemistry"},
+	{"生物", "require_biology"},
+	{"政治", "require_politics"},
+	{"历史", "require_history"},
+	{"地理", "require_geography"},
+}
+
+// 将录取数据转换为报表行
+func buildReportItem(ranks *scorerank.Registry, row *models.AdmissionHubeiWide, province, classFirstChoice string) models.List {
+	// 处理学制字段
+	studyYears := strconv.Itoa(int(row.StudyDuration))
+
+	// 处理专业最低分字段
+	var majorMinScorePtr *uint16
+	var majorMinRank2024Ptr *int
+	if row.MajorMinScore2024 > 0 {
+		majorMinScore := row.MajorMinScore2024
+		majorMinScorePtr = &majorMinScore
+
+		// 直接使用用户选择的首选科目类型，计算专业最低分对应的2024年排名
+		if rank2024, err := ranks.RankByScore(province, models.ReferenceAdmissionYear, classFirstChoice, int(majorMinScore)); err == nil {
+			majorMinRank2024Ptr = &rank2024
+		}
+	}
+
+	// 处理学费字段 - 转换为uint32
+	var tuitionFeeUint32 *uint32
+	if row.TuitionFee != "" {
+		if fee, err := strconv.ParseUint(row.TuitionFee, 10, 32); err == nil {
+			feeUint32 := uint32(fee)
+			tuitionFeeUint32 = &feeUint32
+		}
+	}
+
+	// 转换数据类型 - 确保类型匹配
+	idUint64 := uint64(row.ID)
+	lowestPointsInt64 := int64(row.MinScore2024)
+	lowestRankInt64 := int64(row.MinRank2024)
+	isNewMajor := row.IsNewMajor
+
+	return models.List{
+		ID:                       &idUint64,
+		CollegeName:              &row.SchoolName,
+		CollegeCode:              &row.SchoolCode,
+		SpecialInterestGroupCode: &row.MajorGroupCode,
+		MajorCode:                &row.MajorCode,
+		ClassDemand:              &row.SubjectRequirementRaw,
+		CollegeProvince:          &row.SchoolProvince,
+		CollegeCity:              &row.SchoolCity,
+		CollegeOwnership:         &row.SchoolOwnership,
+		CollegeType:              &row.SchoolType,
+		CollegeAuthority:         &row.SchoolAuthority,
+		CollegeLevel:             &row.SchoolLevel,
+		CollegeTags:              &row.SchoolTags,
+		EducationLevel:           &row.EducationLevel,
+		MajorDescription:         &row.MajorDescription,
+		TuitionFee:               tuitionFeeUint32,
+		IsNewMajor:               &isNewMajor,
+		LowestPoints:             &lowestPointsInt64,
+		LowestRank:               &lowestRankInt64,
+		ProfessionalName:         row.MajorName,
+		StudyYears:               &studyYears,
+		MajorMinScore2024:        majorMinScorePtr,
+		MajorMinRank2024:         majorMinRank2024Ptr,
+	}
+}
+
+// 计算最近n年的年份区间，n小于1时只取当年
+func historyYearRange(year, n int) (fromYear, toYear int) {
+	if n < 1 {
+		n = 1
+	}
+	return year - n + 1, year
+}
+
+// 录取数据涉及的院校代码（去重）
+func distinctSchoolCodes(rows []models.AdmissionHubeiWide) []string {
+	seen := make(map[string]bool)
+	var codes []string
+	for _, row := range rows {
+		if !seen[row.SchoolCode] {
+			seen[row.SchoolCode] = true
+			codes = append(codes, row.SchoolCode)
+		}
+	}
+	return codes
+}
+
+func historyKey(schoolCode, majorGroupCode, majorCode string) string {
+	return schoolCode + "|" + majorGroupCode + "|" + majorCode
+}
+
+// 将历年录取数据附加到报表行，rows与list按下标一一对应
+func attachHistory(list []models.List, rows []models.AdmissionHubeiWide, history []models.AdmissionHistory) {
+	if len(history) == 0 {
+		return
+	}
+
+	// 按年份降序排列
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Year > history[j].Year
+	})
+
+	series := make(map[string][]models.YearScore)
+	for _, h := range history {
+		key := historyKey(h.SchoolCode, h.MajorGroupCode, h.MajorCode)
+		series[key] = append(series[key], models.YearScore{
+			Year:     int(h.Year),
+			MinScore: h.MinScore,
+			MinRank:  h.MinRank,
+		})
+	}
+
+	for i := range list {
+		row := &rows[i]
+		list[i].History = series[historyKey(row.SchoolCode, row.MajorGroupCode, "")]
+		list[i].MajorHistory = series[historyKey(row.SchoolCode, row.MajorGroupCode, row.MajorCode)]
+	}
+}
+
+// 从gaokao2025宽表的 *_2024 字段推导参考年份的历年录取数据，与迁移 0003_create_admission_history 的回填一致
+func deriveReferenceHistory(rows []models.AdmissionHubeiWide) []models.AdmissionHistory {
+	var history []models.AdmissionHistory
+	groups := make(map[string]int)
+	for _, row := range rows {
+		if row.MinScore2024 > 0 {
+			key := row.SourceProvince + "|" + row.SubjectCategory + "|" + historyKey(row.SchoolCode, row.MajorGroupCode, "")
+			if idx, ok := groups[key]; ok {
+				history[idx].EnrollmentPlan += row.EnrollmentPlan2024
+			} else {
+				groups[key] = len(history)
+				history = append(history, models.AdmissionHistory{
+					Year:            models.ReferenceAdmissionYear,
+					SourceProvince:  row.SourceProvince,
+					SubjectCategory: row.SubjectCategory,
+					AdmissionBatch:  row.AdmissionBatch,
+					SchoolCode:      row.SchoolCode,
+					MajorGroupCode:  row.MajorGroupCode,
+					MinScore:        row.MinScore2024,
+					MinRank:         row.MinRank2024,
+					EnrollmentPlan:  row.EnrollmentPlan2024,
+				})
+			}
+		}
+		if row.MajorMinScore2024 > 0 {
+			history = append(history, models.AdmissionHistory{
+				Year:            models.ReferenceAdmissionYear,
+				SourceProvince:  row.SourceProvince,
+				SubjectCategory: row.SubjectCategory,
+				AdmissionBatch:  row.AdmissionBatch,
+				SchoolCode:      row.SchoolCode,
+				MajorGroupCode:  row.MajorGroupCode,
+				MajorCode:       row.MajorCode,
+				MinScore:        row.MajorMinScore2024,
+				EnrollmentPlan:  row.EnrollmentPlan2024,
+			})
+		}
+	}
+	return history
+}
+
+// 将报表行的录取最低分换算为考生高考年份的等位分，缺少一分一段表的年份不换算
+func attachEquivalentScores(ranks *scorerank.Registry, list []models.List, q *models.ReportQuery) {
+	equivalent := func(score, year int) (int, bool) {
+		if score <= 0 {
+			return 0, false
+		}
+		eq, err := ranks.EquivalentScore(q.Province, q.ClassFirstChoice, score, year, q.ExamYear)
+		if err != nil {
+			return 0, false
+		}
+		return eq.EquivalentScore, true
+	}
+
+	for i := range list {
+		item := &list[i]
+		if item.LowestPoints != nil {
+			if score, ok := equivalent(int(*item.LowestPoints), q.Year); ok {
+				v := int64(score)
+				item.EquivalentScore = &v
+			}
+		}
+		if item.MajorMinScore2024 != nil {
+			if score, ok := equivalent(int(*item.MajorMinScore2024), models.ReferenceAdmissionYear); ok {
+				v := int64(score)
+				item.MajorEquivalentScore = &v
+			}
+		}
+		for j := range item.History {
+			item.History[j].EquivalentScore, _ = equivalent(int(item.History[j].MinScore), item.History[j].Year)
+		}
+		for j := range item.MajorHistory {
+			item.MajorHistory[j].EquivalentScore, _ = equivalent(int(item.MajorHistory[j].MinScore), item.MajorHistory[j].Year)
+		}
+	}
+}
+
+// StreamBatchSize 流式读取报表行时每批的行数
+const StreamBatchSize = 500
+
+// 为一批流式读取的报表行附加等位分后交给调用方，空批次不调用
+func flushStreamBatch(ranks *scorerank.Registry, batch []models.List, q *models.ReportQuery, fn func(batch []models.List) error) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	attachEquivalentScores(ranks, batch, q)
+	return fn(batch)
+}
+
+// EnrollmentPlan 招生计划数，没有当年计划时使用参考年份的计划数
+func EnrollmentPlan(row *models.AdmissionHubeiWide) int {
+	if row.EnrollmentPlan > 0 {
+		return int(row.EnrollmentPlan)
+	}
+	return int(row.EnrollmentPlan2024)
+}
+
+// 将报表行按院校专业组聚合，rows与list按下标一一对应，专业组按首次出现的顺序排列
+func buildGroupItems(list []models.List, rows []models.AdmissionHubeiWide) []models.GroupItem {
+	groups := make([]models.GroupItem, 0)
+	index := make(map[string]int)
+	for i := range rows {
+		row, item := &rows[i], &list[i]
+		key := historyKey(row.SchoolCode, row.MajorGroupCode, "")
+		idx, ok := index[key]
+		if !ok {
+			idx = len(groups)
+			index[key] = idx
+			groups = append(groups, models.GroupItem{
+				CollegeName:      row.SchoolName,
+				CollegeCode:      row.SchoolCode,
+				MajorGroupCode:   row.MajorGroupCode,
+				ClassDemand:      row.SubjectRequirementRaw,
+				CollegeProvince:  row.SchoolProvince,
+				CollegeCity:      row.SchoolCity,
+				CollegeOwnership: row.SchoolOwnership,
+				CollegeType:      row.SchoolType,
+				CollegeAuthority: row.SchoolAuthority,
+				CollegeLevel:     row.SchoolLevel,
+				CollegeTags:      row.SchoolTags,
+				EducationLevel:   row.EducationLevel,
+				LowestPoints:     item.LowestPoints,
+				LowestRank:       item.LowestRank,
+				EquivalentScore:  item.EquivalentScore,
+				History:          item.History,
+			})
+		}
+		group := &groups[idx]
+		group.EnrollmentPlan += EnrollmentPlan(row)
+		group.Majors = append(group.Majors, models.GroupMajor{
+			ID:                   uint64(row.ID),
+			MajorCode:            row.MajorCode,
+			ProfessionalName:     row.MajorName,
+			ClassDemand:          row.SubjectRequirementRaw,
+			MajorDescription:     row.MajorDescription,
+			StudyYears:           item.StudyYears,
+			TuitionFee:           item.TuitionFee,
+			IsNewMajor:           row.IsNewMajor,
+			EnrollmentPlan:       EnrollmentPlan(row),
+			MajorMinScore2024:    item.MajorMinScore2024,
+			MajorMinRank2024:     item.MajorMinRank2024,
+			MajorEquivalentScore: item.MajorEquivalentScore,
+			MajorHistory:         item.MajorHistory,
+		})
+	}
+
+	for i := range groups {
+		majors := groups[i].Majors
+		sort.SliceStable(majors, func(a, b int) bool {
+			return majorMinScore(&majors[a]) > majorMinScore(&majors[b])
+		})
+	}
+	return groups
+}
+
+// BuildGroupItems 将录取数据按院校专业组聚合，专业组按首次出现的顺序排列
+func BuildGroupItems(ranks *scorerank.Registry, rows []models.AdmissionHubeiWide, province, subjectCategory string) []models.GroupItem {
+	list := make([]models.List, len(rows))
+	for i := range rows {
+		list[i] = buildReportItem(ranks, &rows[i], province, subjectCategory)
+	}
+	return buildGroupItems(list, rows)
+}
+
+// BuildSchoolDetail 由院校的全部专业构建院校详情，rows 由 GetSchoolMajors 查询，不能为空
+func BuildSchoolDetail(ranks *scorerank.Registry, rows []models.AdmissionHubeiWide, province, subjectCategory, batch string) *models.SchoolDetail {
+	first := &rows[0]
+	detail := &models.SchoolDetail{
+		SchoolCode:      first.SchoolCode,
+		SchoolName:      first.SchoolName,
+		SchoolProvince:  first.SchoolProvince,
+		SchoolCity:      first.SchoolCity,
+		SchoolOwnership: first.SchoolOwnership,
+		SchoolAuthority: first.SchoolAuthority,
+		SchoolLevel:     first.SchoolLevel,
+		SchoolTags:      first.SchoolTags,
+		SchoolType:      first.SchoolType,
+		SubjectCategory: subjectCategory,
+		Batch:           batch,
+		MajorCount:      len(rows),
+		Groups:          BuildGroupItems(ranks, rows, province, subjectCategory),
+	}
+	for i := range detail.Groups {
+		detail.EnrollmentPlan += detail.Groups[i].EnrollmentPlan
+	}
+	return detail
+}
+
+// BuildMajorOfferings 将开设某专业的录取数据转换为院校专业组列表，rows 与返回值按下标一一对应
+func BuildMajorOfferings(ranks *scorerank.Registry, rows []models.AdmissionHubeiWide, province, subjectCategory string) []models.MajorOffering {
+	offerings := make([]models.MajorOffering, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		item := buildReportItem(ranks, row, province, subjectCategory)
+		offering := models.MajorOffering{
+			SchoolCode:        row.SchoolCode,
+			SchoolName:        row.SchoolName,
+			MajorGroupCode:    row.MajorGroupCode,
+			MajorName:         row.MajorName,
+			ClassDemand:       row.SubjectRequirementRaw,
+			SchoolProvince:    row.SchoolProvince,
+			SchoolCity:        row.SchoolCity,
+			SchoolLevel:       row.SchoolLevel,
+			SchoolTags:        row.SchoolTags,
+			StudyYears:        item.StudyYears,
+			TuitionFee:        item.TuitionFee,
+			EnrollmentPlan:    EnrollmentPlan(row),
+			MajorMinScore2024: item.MajorMinScore2024,
+			MajorMinRank2024:  item.MajorMinRank2024,
+		}
+		if row.MinScore2024 > 0 {
+			offering.GroupMinScore2024 = item.LowestPoints
+		}
+		if row.MinRank2024 > 0 {
+			offering.GroupMinRank2024 = item.LowestRank
+		}
+		offerings[i] = offering
+	}
+	return offerings
+}
+
+func majorMinScore(m *models.GroupMajor) uint16 {
+	if m.MajorMinScore2024 == nil {
+		return 0
+	}
+	return *m.MajorMinScore2024
+}
+
+// 院校专业组标识对应的键，与 concat(school_code, '|', major_group_code) 一致
+func groupKeys(groups []models.MajorGroupRef) []string {
+	keys := make([]string, len(groups))
+	for i, g := range groups {
+		keys[i] = g.CollegeCode + "|" + g.MajorGroupCode
+	}
+	return keys
+}
+
+// 构建报表响应，按院校专业组聚合时 totalCount 为专业组总数
+func buildReport(q *models.ReportQuery, list []models.List, rows []models.AdmissionHubeiWide, totalCount int64) *models.Response {
+	if !q.GroupByMajorGroup {
+		return buildReportResponse(list, q.Page, q.PageSize, totalCount)
+	}
+	resp := buildReportResponse([]models.List{}, q.Page, q.PageSize, totalCount)
+	resp.Data.Groups = buildGroupItems(list, rows)
+	return resp
+}
+
+// 构建分页报表响应
+func buildReportResponse(list []models.List, page, pageSize, totalCount int64) *models.Response {
+	totalPages := int64(0)
+	if totalCount > 0 {
+		totalPages = (totalCount + pageSize - 1) / pageSize
+	}
+
+	return &models.Response{
+		Code: 0,
+		Msg:  "success",
+		Data: models.Data{
+			Conf: &models.Conf{
+				Page:        page,
+				PageSize:    pageSize,
+				TotalNumber: totalCount,
+				TotalPage:   totalPages,
+			},
+			List: list,
+		},
+	}
+}
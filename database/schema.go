@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/querybuilder"
+)
+
+// Column 表字段定义，来自模型结构体的 ch/chtype/comment 标签
+type Column struct {
+	Name    string
+	Type    string
+	Comment string
+}
+
+// TableSchema 由模型定义的表结构
+type TableSchema struct {
+	Name    string
+	Comment string
+	Engine  string
+	Columns []Column
+}
+
+// 由模型定义结构的表，迁移执行后按此校验
+var schemaTables = []TableSchema{
+	{
+		Name:    "gaokao2025",
+		Comment: "录取数据宽表",
+		Engine:  "MergeTree()\nORDER BY (id, school_code, major_code)\nSETTINGS index_granularity = 8192",
+		Columns: ModelColumns(models.AdmissionHubeiWide{}),
+	},
+	{
+		Name:    "admission_history",
+		Comment: "历年录取数据长表",
+		Engine:  "ReplacingMergeTree()\nORDER BY (source_province, subject_category, year, school_code, major_group_code, major_code)\nSETTINGS index_granularity = 8192",
+		Columns: ModelColumns(models.AdmissionHistory{}),
+	},
+}
+
+// SchemaTables 返回由模型定义结构的表
+func SchemaTables() []TableSchema {
+	return schemaTables
+}
+
+// ModelColumns 读取模型结构体的字段定义，没有 chtype 标签的字段不属于表结构
+func ModelColumns(model interface{}) []Column {
+	modelType := reflect.TypeOf(model)
+	var columns []Column
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name, chType := field.Tag.Get("ch"), field.Tag.Get("chtype")
+		if name == "" || chType == "" {
+			continue
+		}
+		columns = append(columns, Column{Name: name, Type: chType, Comment: field.Tag.Get("comment")})
+	}
+	return columns
+}
+
+// 字段名列表，用于INSERT语句
+func columnNames(columns []Column) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// CreateTableSQL 生成建表语句（仅用于文档和新迁移的参考，实际建表以迁移脚本为准）
+func (t TableSchema) CreateTableSQL() string {
+	width := 0
+	for _, c := range t.Columns {
+		if len(c.Name) > width {
+			width = len(c.Name)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- %s\nCREATE TABLE IF NOT EXISTS %s (\n", t.Comment, t.Name)
+	for i, c := range t.Columns {
+		fmt.Fprintf(&b, "\t%-*s %s COMMENT '%s'", width, c.Name, c.Type, strings.ReplaceAll(c.Comment, "'", "\\'"))
+		if i < len(t.Columns)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, ") ENGINE = %s;\n", t.Engine)
+	return b.String()
+}
+
+// Markdown 生成字段说明表格
+func (t TableSchema) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s（%s）\n\n", t.Name, t.Comment)
+	b.WriteString("| 字段名 | 类型 | 说明 |\n|--------|------|------|\n")
+	for _, c := range t.Columns {
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n", c.Name, strings.ReplaceAll(c.Type, "|", "\\|"), c.Comment)
+	}
+	return b.String()
+}
+
+// VerifySchema 比较数据库中的表结构与模型定义，缺少字段或类型不一致时返回错误。
+// 数据库中多出的字段只输出警告
+func (db *ClickHouseDB) VerifySchema() error {
+	var problems []string
+	for _, table := range schemaTables {
+		actual, err := db.tableColumns(table.Name)
+		if err != nil {
+			return fmt.Errorf("查询表 %s 结构失败: %v", table.Name, err)
+		}
+		if len(actual) == 0 {
+			problems = append(problems, fmt.Sprintf("表 %s 不存在", table.Name))
+			continue
+		}
+
+		expected := make(map[string]bool)
+		for _, c := range table.Columns {
+			expected[c.Name] = true
+			chType, ok := actual[c.Name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s 缺失", table.Name, c.Name))
+			} else if chType != c.Type {
+				problems = append(problems, fmt.Sprintf("%s.%s 类型为 %s，模型定义为 %s", table.Name, c.Name, chType, c.Type))
+			}
+		}
+		for name := range actual {
+			if !expected[name] {
+				log.Printf("警告: %s.%s 不在模型定义中", table.Name, name)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("表结构与模型定义不一致（需要新增迁移）: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// 查询当前数据库中表的字段名和类型
+func (db *ClickHouseDB) tableColumns(table string) (map[string]string, error) {
+	query, args := querybuilder.Select("name", "type").
+		From("system.columns").
+		Where(querybuilder.Expr("database = currentDatabase()"), querybuilder.Eq("table", table)).
+		Build()
+	rows, err := db.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, chType string
+		if err := rows.Scan(&name, &chType); err != nil {
+			return nil, err
+		}
+		columns[name] = chType
+	}
+	return columns, rows.Err()
+}
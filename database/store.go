@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+
+	"gaokao-zhiyuan/models"
+)
+
+// AdmissionStore 录取数据存储接口
+// handlers 只依赖该接口，ClickHouseDB 与 MemoryDB 都实现了它，
+// 便于切换存储后端以及在没有ClickHouse的环境下运行和测试整个HTTP接口
+type AdmissionStore interface {
+	// 志愿填报报表查询
+	GetReportDataNew(q *models.ReportQuery) (*models.Response, error)
+	// 分批读取全部符合条件的报表行（不分页），用于流式导出
+	StreamReport(ctx context.Context, q *models.ReportQuery, fn func(batch []models.List) error) error
+	// 查询所有符合条件的录取数据（不分页），用于生成志愿表
+	ListAdmissions(q *models.ReportQuery) ([]models.AdmissionHubeiWide, error)
+	// 查询院校专业组内的全部专业（不受报表筛选条件影响），用于调剂风险分析
+	GetGroupMajors(province, subjectCategory, batch string, groups []models.MajorGroupRef) ([]models.AdmissionHubeiWide, error)
+	// 查询院校在某科类、批次的全部专业，用于院校详情
+	GetSchoolMajors(province, subjectCategory, batch, schoolCode string) ([]models.AdmissionHubeiWide, error)
+	// 查询院校在年份区间内的历年录取数据
+	GetAdmissionHistory(province, subjectCategory string, fromYear, toYear int, schoolCodes []string) ([]models.AdmissionHistory, error)
+	// 获取数据记录数
+	GetDataCount() (int64, error)
+	// 关闭存储
+	Close() error
+}
+
+// 编译期检查两种存储实现都满足接口
+var (
+	_ AdmissionStore = (*ClickHouseDB)(nil)
+	_ AdmissionStore = (*MemoryDB)(nil)
+)
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 流式导出格式
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// RowWriter 逐行写出报表行，Flush 将缓冲的数据写入底层 io.Writer
+type RowWriter interface {
+	ContentType() string
+	WriteHeader() error
+	Write(item *models.List) error
+	Flush() error
+}
+
+// NewRowWriter 按格式创建报表行写出器，format 可选 csv、ndjson
+func NewRowWriter(w io.Writer, format string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{out: w, w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s（可选 csv、ndjson）", format)
+	}
+}
+
+// CSV的列：表头及从报表行取值的方法
+var csvColumns = []struct {
+	header string
+	value  func(item *models.List) string
+}{
+	{"院校代码", func(item *models.List) string { return deref(item.CollegeCode) }},
+	{"院校名称", func(item *models.List) string { return deref(item.CollegeName) }},
+	{"专业组代码", func(item *models.List) string { return deref(item.SpecialInterestGroupCode) }},
+	{"专业名称", func(item *models.List) string { return item.ProfessionalName }},
+	{"选科要求", func(item *models.List) string { return deref(item.ClassDemand) }},
+	{"院校所在省份", func(item *models.List) string { return deref(item.CollegeProvince) }},
+	{"院校所在城市", func(item *models.List) string { return deref(item.CollegeCity) }},
+	{"院校层次", func(item *models.List) string { return deref(item.CollegeLevel) }},
+	{"院校标签", func(item *models.List) string { return deref(item.CollegeTags) }},
+	{"公私性质", func(item *models.List) string { return deref(item.CollegeOwnership) }},
+	{"院校类型", func(item *models.List) string { return deref(item.CollegeType) }},
+	{"学历层次", func(item *models.List) string { return deref(item.EducationLevel) }},
+	{"学制", func(item *models.List) string { return deref(item.StudyYears) }},
+	{"学费（元/年）", func(item *models.List) string { return fee(item.TuitionFee) }},
+	{"新增专业", func(item *models.List) string {
+		if item.IsNewMajor != nil && *item.IsNewMajor {
+			return "是"
+		}
+		return "否"
+	}},
+	{"专业组最低分", func(item *models.List) string { return formatInt(item.LowestPoints) }},
+	{"专业组最低位次", func(item *models.List) string { return formatInt(item.LowestRank) }},
+	{"专业组最低分等位分", func(item *models.List) string { return formatInt(item.EquivalentScore) }},
+	{"专业最低分", func(item *models.List) string { return formatInt(scoreOf(item.MajorMinScore2024)) }},
+	{"专业最低位次", func(item *models.List) string { return formatInt(rankOf(item.MajorMinRank2024)) }},
+	{"专业最低分等位分", func(item *models.List) string { return formatInt(item.MajorEquivalentScore) }},
+	{"录取概率", func(item *models.List) string {
+		if item.AdmissionProbability == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*item.AdmissionProbability, 'f', -1, 64)
+	}},
+	{"冲稳保", func(item *models.List) string { return item.Tier }},
+	{"线差", func(item *models.List) string {
+		if item.LineDiff == nil {
+			return ""
+		}
+		return strconv.Itoa(*item.LineDiff)
+	}},
+}
+
+// CSV，带UTF-8 BOM以便Excel正确识别中文
+type csvWriter struct {
+	out    io.Writer
+	w      *csv.Writer
+	record []string
+}
+
+func (w *csvWriter) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (w *csvWriter) WriteHeader() error {
+	if _, err := io.WriteString(w.out, "\ufeff"); err != nil {
+		return err
+	}
+	header := make([]string, len(csvColumns))
+	for i, col := range csvColumns {
+		header[i] = col.header
+	}
+	return w.w.Write(header)
+}
+
+func (w *csvWriter) Write(item *models.List) error {
+	if w.record == nil {
+		w.record = make([]string, len(csvColumns))
+	}
+	for i, col := range csvColumns {
+		w.record[i] = col.value(item)
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// 每行一个JSON对象，字段同报表查询接口的 list
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) ContentType() string { return "application/x-ndjson" }
+
+func (w *ndjsonWriter) WriteHeader() error { return nil }
+
+func (w *ndjsonWriter) Write(item *models.List) error { return w.enc.Encode(item) }
+
+func (w *ndjsonWriter) Flush() error { return nil }
+
+func formatInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+func TestRowWriter(t *testing.T) {
+	code, name := "10487", "华中科技大学"
+	points, p := int64(640), 0.213
+	item := &models.List{CollegeCode: &code, CollegeName: &name, ProfessionalName: "软件工程", LowestPoints: &points, AdmissionProbability: &p, Tier: "冲"}
+
+	var buf bytes.Buffer
+	w, err := NewRowWriter(&buf, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "\ufeff院校代码,院校名称,") {
+		t.Fatalf("csv = %q", buf.String())
+	}
+	fields := strings.Split(lines[1], ",")
+	if len(fields) != len(csvColumns) || fields[0] != code || fields[3] != "软件工程" || fields[15] != "640" || fields[21] != "0.213" || fields[22] != "冲" {
+		t.Errorf("csv row = %q", lines[1])
+	}
+
+	buf.Reset()
+	if w, err = NewRowWriter(&buf, FormatNDJSON); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.Write(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ndjson lines = %d, want 2", len(lines))
+	}
+	var got models.List
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil || got.ProfessionalName != "软件工程" || got.Tier != "冲" {
+		t.Errorf("ndjson = %q, err = %v", lines[0], err)
+	}
+
+	if _, err := NewRowWriter(&buf, "xml"); err == nil {
+		t.Error("NewRowWriter(xml) should fail")
+	}
+}
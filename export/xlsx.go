@@ -0,0 +1,556 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 湖北省每个院校专业组志愿可填报的专业数
+const MajorsPerGroup = 6
+
+// 工作表名称
+const (
+	formSheet    = "志愿表"
+	detailSheet  = "专业明细"
+	summarySheet = "汇总"
+)
+
+// Criterion 汇总表中列出的一项筛选条件
+type Criterion struct {
+	Name  string
+	Value string
+}
+
+// 冲稳保分档的填充色，依次为冲、稳、保
+var tierColors = map[string]string{
+	"冲": "FFC7CE",
+	"稳": "FFEB9C",
+	"保": "C6EFCE",
+}
+
+// 汇总表中分档的顺序，未分档为不在冲稳保任何分档的行
+var tierOrder = []string{"冲", "稳", "保", "未分档"}
+
+// 志愿表中的一个院校专业组，列顺序同湖北省普通高校招生志愿表：
+// 志愿序号、院校代码、院校名称、专业组代码、专业1-6、是否服从专业调剂，其后为参考数据
+type formRow struct {
+	tier         string
+	collegeCode  string
+	collegeName  string
+	groupCode    string
+	majors       []string // 专业代码+名称
+	classDemand  string
+	location     string
+	tuition      string
+	lowestPoints *int64
+	lowestRank   *int64
+	probability  *float64
+	remark       string
+}
+
+// 专业明细表中的一个专业
+type detailRow struct {
+	tier          string
+	collegeCode   string
+	collegeName   string
+	groupCode     string
+	majorCode     string
+	majorName     string
+	classDemand   string
+	studyYears    string
+	tuition       string
+	majorMinScore *int64
+	majorMinRank  *int64
+	groupMinScore *int64
+	groupMinRank  *int64
+	probability   *float64
+}
+
+// ReportWorkbook 将报表查询结果导出为xlsx：志愿表按院校专业组排列，专业明细每行一个专业，
+// 汇总表统计冲稳保各档的专业组数并列出筛选条件。year 为参考录取年份，用于录取分数线的表头
+func ReportWorkbook(resp *models.Response, year int, criteria []Criterion) (*excelize.File, error) {
+	var forms []formRow
+	var details []detailRow
+	if len(resp.Data.Groups) > 0 {
+		forms, details = groupRows(resp.Data.Groups)
+	} else {
+		forms, details = listRows(resp.Data.List)
+	}
+
+	w, err := newWorkbook(year)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeForm(forms); err != nil {
+		return nil, err
+	}
+	if err := w.writeDetails(details); err != nil {
+		return nil, err
+	}
+	if err := w.writeSummary(forms, nil, criteria); err != nil {
+		return nil, err
+	}
+	return w.file, nil
+}
+
+// PlanWorkbook 将志愿表导出为xlsx，汇总表同时列出按比例分配的各档志愿数
+func PlanWorkbook(plan *models.Plan, year int, criteria []Criterion) (*excelize.File, error) {
+	forms := make([]formRow, len(plan.Items))
+	for i, item := range plan.Items {
+		majors := make([]string, len(item.Majors))
+		var tuition []string
+		for j, m := range item.Majors {
+			majors[j] = majorLabel(m.MajorCode, m.MajorName)
+			if m.TuitionFee != "" {
+				tuition = append(tuition, m.TuitionFee)
+			}
+		}
+		points, rank, p := item.LowestPoints, item.LowestRank, item.AdmissionProbability
+		forms[i] = formRow{
+			tier:         item.Tier,
+			collegeCode:  item.CollegeCode,
+			collegeName:  item.CollegeName,
+			groupCode:    item.MajorGroupCode,
+			majors:       majors,
+			classDemand:  item.ClassDemand,
+			location:     location(item.CollegeProvince, item.CollegeCity),
+			tuition:      tuitionRange(tuition),
+			lowestPoints: &points,
+			lowestRank:   &rank,
+			probability:  &p,
+			remark:       item.Explanation,
+		}
+	}
+
+	w, err := newWorkbook(year)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeForm(forms); err != nil {
+		return nil, err
+	}
+	if err := w.writeSummary(forms, &plan.Quota, criteria); err != nil {
+		return nil, err
+	}
+	return w.file, nil
+}
+
+// 按院校专业组聚合的报表行
+func groupRows(groups []models.GroupItem) ([]formRow, []detailRow) {
+	forms := make([]formRow, len(groups))
+	var details []detailRow
+	for i := range groups {
+		g := &groups[i]
+		majors := make([]string, len(g.Majors))
+		var tuition []string
+		for j := range g.Majors {
+			m := &g.Majors[j]
+			majors[j] = majorLabel(m.MajorCode, m.ProfessionalName)
+			if m.TuitionFee != nil {
+				tuition = append(tuition, strconv.FormatUint(uint64(*m.TuitionFee), 10))
+			}
+			detail := detailRow{
+				tier:          g.Tier,
+				collegeCode:   g.CollegeCode,
+				collegeName:   g.CollegeName,
+				groupCode:     g.MajorGroupCode,
+				majorCode:     m.MajorCode,
+				majorName:     m.ProfessionalName,
+				classDemand:   valueOr(m.ClassDemand, g.ClassDemand),
+				studyYears:    deref(m.StudyYears),
+				tuition:       fee(m.TuitionFee),
+				majorMinScore: scoreOf(m.MajorMinScore2024),
+				majorMinRank:  rankOf(m.MajorMinRank2024),
+				groupMinScore: g.LowestPoints,
+				groupMinRank:  g.LowestRank,
+				probability:   g.AdmissionProbability,
+			}
+			details = append(details, detail)
+		}
+		forms[i] = formRow{
+			tier:         g.Tier,
+			collegeCode:  g.CollegeCode,
+			collegeName:  g.CollegeName,
+			groupCode:    g.MajorGroupCode,
+			majors:       majors,
+			classDemand:  g.ClassDemand,
+			location:     location(g.CollegeProvince, g.CollegeCity),
+			tuition:      tuitionRange(tuition),
+			lowestPoints: g.LowestPoints,
+			lowestRank:   g.LowestRank,
+			probability:  g.AdmissionProbability,
+		}
+	}
+	return forms, details
+}
+
+// 按专业的报表行，同一院校专业组的专业按出现顺序合并到志愿表的一行
+func listRows(list []models.List) ([]formRow, []detailRow) {
+	var forms []formRow
+	index := make(map[string]int)
+	tuition := make(map[int][]string)
+	details := make([]detailRow, len(list))
+	for i := range list {
+		item := &list[i]
+		collegeCode, groupCode := deref(item.CollegeCode), deref(item.SpecialInterestGroupCode)
+		details[i] = detailRow{
+			tier:          item.Tier,
+			collegeCode:   collegeCode,
+			collegeName:   deref(item.CollegeName),
+			groupCode:     groupCode,
+			majorCode:     deref(item.MajorCode),
+			majorName:     item.ProfessionalName,
+			classDemand:   deref(item.ClassDemand),
+			studyYears:    deref(item.StudyYears),
+			tuition:       fee(item.TuitionFee),
+			majorMinScore: scoreOf(item.MajorMinScore2024),
+			majorMinRank:  rankOf(item.MajorMinRank2024),
+			groupMinScore: item.LowestPoints,
+			groupMinRank:  item.LowestRank,
+			probability:   item.AdmissionProbability,
+		}
+
+		key := collegeCode + "|" + groupCode
+		idx, ok := index[key]
+		if !ok {
+			idx = len(forms)
+			index[key] = idx
+			forms = append(forms, formRow{
+				tier:         item.Tier,
+				collegeCode:  collegeCode,
+				collegeName:  deref(item.CollegeName),
+				groupCode:    groupCode,
+				classDemand:  deref(item.ClassDemand),
+				location:     location(deref(item.CollegeProvince), deref(item.CollegeCity)),
+				lowestPoints: item.LowestPoints,
+				lowestRank:   item.LowestRank,
+				probability:  item.AdmissionProbability,
+			})
+		}
+		forms[idx].majors = append(forms[idx].majors, majorLabel(deref(item.MajorCode), item.ProfessionalName))
+		if item.TuitionFee != nil {
+			tuition[idx] = append(tuition[idx], strconv.FormatUint(uint64(*item.TuitionFee), 10))
+		}
+	}
+	for idx := range forms {
+		forms[idx].tuition = tuitionRange(tuition[idx])
+	}
+	return forms, details
+}
+
+// 工作簿及其单元格样式
+type workbook struct {
+	file    *excelize.File
+	year    int // 参考录取年份
+	header  int
+	body    map[string]int // 分档 -> 正文样式，空分档无填充色
+	percent map[string]int // 分档 -> 百分比样式
+}
+
+func newWorkbook(year int) (*workbook, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", formSheet); err != nil {
+		return nil, err
+	}
+	w := &workbook{file: f, year: year, body: make(map[string]int), percent: make(map[string]int)}
+
+	border := []excelize.Border{
+		{Type: "left", Color: "BFBFBF", Style: 1},
+		{Type: "right", Color: "BFBFBF", Style: 1},
+		{Type: "top", Color: "BFBFBF", Style: 1},
+		{Type: "bottom", Color: "BFBFBF", Style: 1},
+	}
+	var err error
+	w.header, err = f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+		Border:    border,
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, tier := range []string{"", "冲", "稳", "保"} {
+		style := excelize.Style{Border: border, Alignment: &excelize.Alignment{Vertical: "center"}}
+		if color, ok := tierColors[tier]; ok {
+			style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}}
+		}
+		if w.body[tier], err = f.NewStyle(&style); err != nil {
+			return nil, err
+		}
+		format := "0.0%"
+		style.CustomNumFmt = &format
+		if w.percent[tier], err = f.NewStyle(&style); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// 带参考录取年份的表头，如 "2024年最低分"
+func (w *workbook) yearLabel(name string) string {
+	return fmt.Sprintf("%d年%s", w.year, name)
+}
+
+// 写入表头并冻结首行
+func (w *workbook) writeHeader(sheet string, header []string, widths []float64) error {
+	if err := w.file.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	last, _ := excelize.ColumnNumberToName(len(header))
+	if err := w.file.SetCellStyle(sheet, "A1", last+"1", w.header); err != nil {
+		return err
+	}
+	for i, width := range widths {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := w.file.SetColWidth(sheet, col, col, width); err != nil {
+			return err
+		}
+	}
+	return w.file.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	})
+}
+
+// 写入一行正文，按分档着色，percentCol 为录取概率所在列（从1开始）
+func (w *workbook) writeRow(sheet string, row int, values []interface{}, tier string, percentCol int) error {
+	cell, _ := excelize.CoordinatesToCellName(1, row)
+	if err := w.file.SetSheetRow(sheet, cell, &values); err != nil {
+		return err
+	}
+	if _, ok := w.body[tier]; !ok {
+		tier = ""
+	}
+	last, _ := excelize.CoordinatesToCellName(len(values), row)
+	if err := w.file.SetCellStyle(sheet, cell, last, w.body[tier]); err != nil {
+		return err
+	}
+	percent, _ := excelize.CoordinatesToCellName(percentCol, row)
+	return w.file.SetCellStyle(sheet, percent, percent, w.percent[tier])
+}
+
+func (w *workbook) writeForm(rows []formRow) error {
+	header := []string{"志愿序号", "院校代码", "院校名称", "专业组代码"}
+	widths := []float64{10, 10, 24, 12}
+	for i := 1; i <= MajorsPerGroup; i++ {
+		header = append(header, fmt.Sprintf("专业%d", i))
+		widths = append(widths, 22)
+	}
+	header = append(header, "是否服从专业调剂", "冲稳保", "录取概率", w.yearLabel("最低分"), w.yearLabel("最低位次"), "选科要求", "所在地", "学费（元/年）", "备注")
+	widths = append(widths, 10, 8, 10, 12, 14, 14, 14, 14, 40)
+	if err := w.writeHeader(formSheet, header, widths); err != nil {
+		return err
+	}
+	percentCol := 4 + MajorsPerGroup + 3
+
+	for i, r := range rows {
+		values := []interface{}{i + 1, r.collegeCode, r.collegeName, r.groupCode}
+		for j := 0; j < MajorsPerGroup; j++ {
+			var major interface{}
+			if j < len(r.majors) {
+				major = r.majors[j]
+			}
+			values = append(values, major)
+		}
+		remark := r.remark
+		if extra := len(r.majors) - MajorsPerGroup; extra > 0 {
+			remark = strings.TrimSpace(fmt.Sprintf("组内另有%d个专业 %s", extra, remark))
+		}
+		// 是否服从专业调剂由考生填写
+		values = append(values, nil, r.tier, ratio(r.probability), number(r.lowestPoints), number(r.lowestRank),
+			r.classDemand, r.location, r.tuition, remark)
+		if err := w.writeRow(formSheet, i+2, values, r.tier, percentCol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *workbook) writeDetails(rows []detailRow) error {
+	if _, err := w.file.NewSheet(detailSheet); err != nil {
+		return err
+	}
+	header := []string{"院校代码", "院校名称", "专业组代码", "专业代码", "专业名称", "选科要求", "学制", "学费（元/年）",
+		w.yearLabel("专业最低分"), w.yearLabel("专业最低位次"), w.yearLabel("专业组最低分"), w.yearLabel("专业组最低位次"), "录取概率", "冲稳保"}
+	widths := []float64{10, 24, 12, 10, 24, 14, 8, 12, 14, 16, 16, 18, 10, 8}
+	if err := w.writeHeader(detailSheet, header, widths); err != nil {
+		return err
+	}
+	for i, r := range rows {
+		values := []interface{}{r.collegeCode, r.collegeName, r.groupCode, r.majorCode, r.majorName, r.classDemand, r.studyYears, r.tuition,
+			number(r.majorMinScore), number(r.majorMinRank), number(r.groupMinScore), number(r.groupMinRank), ratio(r.probability), r.tier}
+		if err := w.writeRow(detailSheet, i+2, values, r.tier, 13); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 汇总表：冲稳保各档专业组数（志愿表时含按比例分配的志愿数）及筛选条件
+func (w *workbook) writeSummary(rows []formRow, quota *models.PlanQuota, criteria []Criterion) error {
+	if _, err := w.file.NewSheet(summarySheet); err != nil {
+		return err
+	}
+	header := []string{"分档", "专业组数"}
+	if quota != nil {
+		header = append(header, "分配志愿数")
+	}
+	if err := w.writeHeader(summarySheet, header, []float64{16, 40, 12}); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, r := range rows {
+		tier := r.tier
+		if _, ok := tierColors[tier]; !ok {
+			tier = "未分档"
+		}
+		counts[tier]++
+	}
+	quotas := map[string]int{}
+	if quota != nil {
+		quotas = map[string]int{"冲": quota.Chong, "稳": quota.Wen, "保": quota.Bao}
+	}
+	row := 2
+	for _, tier := range tierOrder {
+		values := []interface{}{tier, counts[tier]}
+		if quota != nil {
+			values = append(values, quotas[tier])
+		}
+		if err := w.writeTierRow(row, values, tier); err != nil {
+			return err
+		}
+		row++
+	}
+	total := []interface{}{"合计", len(rows)}
+	if quota != nil {
+		total = append(total, quota.Chong+quota.Wen+quota.Bao)
+	}
+	if err := w.writeTierRow(row, total, ""); err != nil {
+		return err
+	}
+
+	// 筛选条件与统计之间空一行
+	row += 2
+	cell, _ := excelize.CoordinatesToCellName(1, row)
+	if err := w.file.SetSheetRow(summarySheet, cell, &[]string{"筛选条件", "取值"}); err != nil {
+		return err
+	}
+	end, _ := excelize.CoordinatesToCellName(2, row)
+	if err := w.file.SetCellStyle(summarySheet, cell, end, w.header); err != nil {
+		return err
+	}
+	for _, c := range criteria {
+		row++
+		if err := w.writeTierRow(row, []interface{}{c.Name, c.Value}, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *workbook) writeTierRow(row int, values []interface{}, tier string) error {
+	cell, _ := excelize.CoordinatesToCellName(1, row)
+	if err := w.file.SetSheetRow(summarySheet, cell, &values); err != nil {
+		return err
+	}
+	if _, ok := w.body[tier]; !ok {
+		tier = ""
+	}
+	last, _ := excelize.CoordinatesToCellName(len(values), row)
+	return w.file.SetCellStyle(summarySheet, cell, last, w.body[tier])
+}
+
+// 专业代码和名称，没有专业代码时只有名称
+func majorLabel(code, name string) string {
+	return strings.TrimSpace(code + " " + name)
+}
+
+func location(province, city string) string {
+	return strings.TrimSpace(province + " " + city)
+}
+
+// 专业组内学费，不同时为 最低-最高
+func tuitionRange(fees []string) string {
+	var low, high int
+	for _, s := range fees {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			continue
+		}
+		if low == 0 || v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+	switch {
+	case high == 0:
+		return ""
+	case low == high:
+		return strconv.Itoa(low)
+	default:
+		return fmt.Sprintf("%d-%d", low, high)
+	}
+}
+
+func fee(v *uint32) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+func scoreOf(v *uint16) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
+
+func rankOf(v *int) *int64 {
+	if v == nil || *v <= 0 {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// 空值写为空单元格
+func number(v *int64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func ratio(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+func TestReportWorkbookFormLayout(t *testing.T) {
+	code, group, name := "10487", "01", "华中科技大学"
+	points, rank, p := int64(640), int64(5000), 0.213
+	list := make([]models.List, 0, 8)
+	for i, major := range []string{"计算机科学与技术", "软件工程", "人工智能", "网络工程", "信息安全", "物联网工程", "数据科学"} {
+		majorCode := fmt.Sprintf("08090%d", i+1)
+		list = append(list, models.List{
+			CollegeCode: &code, SpecialInterestGroupCode: &group, CollegeName: &name, MajorCode: &majorCode, ProfessionalName: major,
+			LowestPoints: &points, LowestRank: &rank, AdmissionProbability: &p, Tier: "冲",
+		})
+	}
+	f, err := ReportWorkbook(&models.Response{Data: models.Data{List: list}}, 2023, []Criterion{{Name: "省份", Value: "湖北"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got, want := f.GetSheetList(), []string{formSheet, detailSheet, summarySheet}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sheets = %v, want %v", got, want)
+	}
+	rows, err := f.GetRows(formSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一专业组的7个专业合并为一行，志愿表只能填6个专业
+	if len(rows) != 2 {
+		t.Fatalf("form rows = %d, want 2", len(rows))
+	}
+	if got := rows[1][4 : 4+MajorsPerGroup]; got[0] != "080901 计算机科学与技术" || got[5] != "080906 物联网工程" {
+		t.Errorf("majors = %v", got)
+	}
+	if got := rows[1][len(rows[1])-1]; got != "组内另有1个专业" {
+		t.Errorf("remark = %q", got)
+	}
+	if got := rows[0][13]; got != "2023年最低分" {
+		t.Errorf("header = %q, want 2023年最低分", got)
+	}
+	if got := rows[1][12]; got != "21.3%" {
+		t.Errorf("probability = %q, want 21.3%%", got)
+	}
+
+	panes, err := f.GetPanes(formSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panes.Freeze || panes.TopLeftCell != "A2" {
+		t.Errorf("panes = %+v, want header frozen", panes)
+	}
+	styleID, err := f.GetCellStyle(formSheet, "C2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(style.Fill.Color) == 0 || style.Fill.Color[0] != tierColors["冲"] {
+		t.Errorf("fill = %+v, want %s", style.Fill, tierColors["冲"])
+	}
+
+	summary, err := f.GetRows(summarySheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(summary[1], []string{"冲", "1"}) || !reflect.DeepEqual(summary[5], []string{"合计", "1"}) {
+		t.Errorf("summary = %v", summary)
+	}
+	if last := summary[len(summary)-1]; !reflect.DeepEqual(last, []string{"省份", "湖北"}) {
+		t.Errorf("criteria = %v", last)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+	"gaokao-zhiyuan/subjectreq"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 一次最多对比的选科组合数（六选三共20种）
+const maxCombinations = 20
+
+// 差异专业默认列出的个数
+const defaultCombinationDiffLimit = 100
+
+// 选科组合对比接口，统计每个组合可报考的专业和专业组，第一个组合为对比基准
+// POST /api/v1/subjects/compare
+// {"province":"湖北","combinations":["物化生","物化地"],"limit":100}
+func (h *Handler) CompareSubjectCombinations(c *gin.Context) {
+	var req struct {
+		Province     string   `json:"province"`
+		Batch        string   `json:"batch"`
+		Combinations []string `json:"combinations" binding:"required,min=1"` // 如 物化生、物理+化学+地理
+		Limit        int      `json:"limit"`                                 // 多出、少的专业最多列出的个数
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if len(req.Combinations) > maxCombinations {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("一次最多对比%d个选科组合", maxCombinations),
+		})
+		return
+	}
+	if req.Limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "limit参数错误",
+		})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultCombinationDiffLimit
+	}
+
+	profile, ok := resolveProvince(c, req.Province)
+	if !ok {
+		return
+	}
+	batch, ok := resolveBatch(c, profile, req.Batch)
+	if !ok {
+		return
+	}
+
+	log.Printf("选科组合对比请求: %+v", req)
+
+	// 每个科类只查询一次
+	rowsByCategory := make(map[string][]models.AdmissionHubeiWide)
+	inputs := make([]recommend.CombinationInput, len(req.Combinations))
+	for i, name := range req.Combinations {
+		subjects, err := subjectreq.ParseCombination(name)
+		if err == nil && len(subjects) != 3 {
+			err = fmt.Errorf("应为3个科目")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  fmt.Sprintf("选科组合 %s 错误: %v", name, err),
+			})
+			return
+		}
+
+		// 3+1+2 省份的科类为组合中的首选科目，综合改革省份只有一个科类
+		category := profile.DefaultCategory
+		if len(profile.SubjectCategories) > 1 {
+			category = ""
+			for _, subject := range subjects {
+				if profile.HasCategory(subject) {
+					if category != "" {
+						category = ""
+						break
+					}
+					category = subject
+				}
+			}
+			if category == "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code": 1,
+					"msg":  fmt.Sprintf("选科组合 %s 错误: 首选科目应为%v中的一科", name, profile.SubjectCategories),
+				})
+				return
+			}
+		}
+
+		rows, ok := rowsByCategory[category]
+		if !ok {
+			rows, err = h.db.ListAdmissions(&models.ReportQuery{
+				Year:             models.ReferenceAdmissionYear,
+				Province:         profile.Name,
+				ClassFirstChoice: category,
+				Batch:            batch,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code": 1,
+					"msg":  "查询失败: " + err.Error(),
+				})
+				return
+			}
+			rowsByCategory[category] = rows
+		}
+		inputs[i] = recommend.CombinationInput{Name: name, SubjectCategory: category, Subjects: subjects, Rows: rows}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": models.CombinationComparison{
+			Province:     profile.Name,
+			Batch:        batch,
+			Combinations: recommend.CompareCombinations(h.tax, inputs, req.Limit),
+		},
+	})
+}
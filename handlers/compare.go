@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 一次对比的院校专业组数
+const (
+	minCompareGroups = 2
+	maxCompareGroups = 10
+)
+
+// 院校专业组对比接口，返回按请求顺序对齐的对比表及考生对各专业组的录取概率
+// POST /api/v1/compare
+// {"rank":6000,"class_first_choise":"物理","groups":[{"college_code":"10487","major_group_code":"01"},{"college_code":"10486","major_group_code":"02"}]}
+func (h *Handler) CompareGroups(c *gin.Context) {
+	var req struct {
+		Rank             int64                  `json:"rank"`
+		Score            int64                  `json:"score"`
+		ExamYear         int                    `json:"exam_year"`
+		Province         string                 `json:"province"`
+		ClassFirstChoice string                 `json:"class_first_choise"`
+		Batch            string                 `json:"batch"`
+		Groups           []models.MajorGroupRef `json:"groups" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Rank <= 0 && req.Score <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少rank或score参数",
+		})
+		return
+	}
+	if len(req.Groups) < minCompareGroups || len(req.Groups) > maxCompareGroups {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("groups数量应为%d-%d个", minCompareGroups, maxCompareGroups),
+		})
+		return
+	}
+	seen := make(map[models.MajorGroupRef]bool)
+	for _, ref := range req.Groups {
+		if seen[ref] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  fmt.Sprintf("重复的院校专业组: %s %s", ref.CollegeCode, ref.MajorGroupCode),
+			})
+			return
+		}
+		seen[ref] = true
+	}
+
+	profile, category, batch, ok := resolveScope(c, req.Province, req.ClassFirstChoice, req.Batch)
+	if !ok {
+		return
+	}
+	if req.ExamYear == 0 {
+		req.ExamYear = models.ReferenceAdmissionYear
+	}
+
+	log.Printf("院校专业组对比请求: %+v", req)
+
+	query := &models.ReportQuery{
+		Year:             models.ReferenceAdmissionYear,
+		ExamYear:         req.ExamYear,
+		Rank:             req.Rank,
+		Province:         profile.Name,
+		ClassFirstChoice: category,
+		Batch:            batch,
+	}
+	st, ok := h.resolveStudent(c, h.model, recommend.MethodScore, query, "", req.Score)
+	if !ok {
+		return
+	}
+
+	rows, err := h.db.GetGroupMajors(profile.Name, category, batch, req.Groups)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+	groups := database.BuildGroupItems(h.ranks, rows, profile.Name, category)
+	recommend.AnnotateGroups(groups, st.estimate, st.window, recommend.MethodScore)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": recommend.BuildGroupComparison(req.Groups, groups, query.Year, query.Rank),
+	})
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/export"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// 导出格式，为空时返回JSON
+const formatXLSX = "xlsx"
+
+// 报表xlsx导出不分页，最多导出的报表行数（按专业组聚合时为专业组数），超出部分不导出
+const maxXLSXReportRows = 2000
+
+// 解析format参数，可选 json、xlsx
+func parseFormat(c *gin.Context) (string, bool) {
+	format := c.Query("format")
+	switch format {
+	case "", "json":
+		return "", true
+	case formatXLSX:
+		return format, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "format参数错误，可选 json、xlsx",
+		})
+		return "", false
+	}
+}
+
+// 以附件形式返回xlsx工作簿
+func writeWorkbook(c *gin.Context, f *excelize.File, err error, filename string) {
+	if err != nil {
+		log.Printf("生成xlsx失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "生成xlsx失败: " + err.Error(),
+		})
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	if err := f.Write(c.Writer); err != nil {
+		log.Printf("写入xlsx失败: %v", err)
+	}
+}
+
+// 汇总表中的筛选条件，取值为空的不列出
+type criteria []export.Criterion
+
+func (cs *criteria) add(name, value string) {
+	if value != "" {
+		*cs = append(*cs, export.Criterion{Name: name, Value: value})
+	}
+}
+
+func (cs *criteria) addList(name string, values []string) {
+	cs.add(name, strings.Join(values, "、"))
+}
+
+// 考生及查询范围
+func (cs *criteria) addStudent(q *models.ReportQuery, score int64, method string) {
+	cs.add("省份", q.Province)
+	cs.add("首选科目", q.ClassFirstChoice)
+	cs.addList("再选科目", q.ClassOptionalChoice)
+	cs.add("批次", q.Batch)
+	cs.add("考生位次", strconv.FormatInt(q.Rank, 10))
+	if score > 0 {
+		cs.add("考生分数", strconv.FormatInt(score, 10))
+	}
+	cs.add("高考年份", strconv.Itoa(q.ExamYear))
+	cs.add("参考录取年份", strconv.Itoa(q.Year))
+	cs.add("冲稳保划分方法", method)
+	cs.addList("院校所在地", q.CollegeLocation)
+	cs.add("专业关键词", q.FuzzySubjectCategory)
+}
+
+// 结构化筛选条件
+func (cs *criteria) addFilters(f *models.ReportFilters) {
+	strs := []struct {
+		name   string
+		filter models.StringFilter
+	}{
+		{"院校标签", f.SchoolTags},
+		{"院校水平层次", f.SchoolLevel},
+		{"公私性质", f.SchoolOwnership},
+		{"院校类型", f.SchoolType},
+		{"院校所在省份", f.SchoolProvince},
+		{"院校所在城市", f.SchoolCity},
+		{"学历层次", f.EducationLevel},
+		{"录取批次", f.AdmissionBatch},
+	}
+	for _, s := range strs {
+		var parts []string
+		if len(s.filter.Include) > 0 {
+			parts = append(parts, "包含 "+strings.Join(s.filter.Include, "、"))
+		}
+		if len(s.filter.Exclude) > 0 {
+			parts = append(parts, "排除 "+strings.Join(s.filter.Exclude, "、"))
+		}
+		cs.add(s.name, strings.Join(parts, "；"))
+	}
+
+	ranges := []struct {
+		name   string
+		filter models.RangeFilter
+	}{
+		{"学费（元/年）", f.TuitionFee},
+		{"学制（年）", f.StudyDuration},
+	}
+	for _, r := range ranges {
+		if r.filter.Min == nil && r.filter.Max == nil {
+			continue
+		}
+		bound := func(v *int64) string {
+			if v == nil {
+				return "不限"
+			}
+			return strconv.FormatInt(*v, 10)
+		}
+		cs.add(r.name, bound(r.filter.Min)+" - "+bound(r.filter.Max))
+	}
+	if f.IsNewMajor != nil {
+		cs.add("新增专业", map[bool]string{true: "是", false: "否"}[*f.IsNewMajor])
+	}
+}
+
+// 冲稳保策略，0冲 1稳 2保，其他值为冲稳保混合
+func strategyName(strategy int) string {
+	switch strategy {
+	case 0:
+		return "冲"
+	case 1:
+		return "稳"
+	case 2:
+		return "保"
+	default:
+		return "冲稳保混合"
+	}
+}
+
+// 报表流式导出接口，按报表查询条件导出全部报表行（不分页），边查询边以分块传输返回，
+// 每批报表行写出后刷新一次，内存占用与导出行数无关
+// POST /api/v1/report/export?format=csv
+// {"rank":6000,"class_first_choise":"物理","strategy":3}
+func (h *Handler) ExportReport(c *gin.Context) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	w, err := export.NewRowWriter(c.Writer, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "format参数错误: " + err.Error(),
+		})
+		return
+	}
+	req, ok := bindReportRequest(c)
+	if !ok {
+		return
+	}
+	if req.GroupBy != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "导出接口不支持group_by参数",
+		})
+		return
+	}
+	query, st, method, ok := h.prepareReport(c, req)
+	if !ok {
+		return
+	}
+
+	// 查到第一批数据后才写出响应头，查询失败时仍可以返回错误信息
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", w.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="report.%s"`, format))
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Status(http.StatusOK)
+		return w.WriteHeader()
+	}
+	total := 0
+	err = h.db.StreamReport(c.Request.Context(), query, func(batch []models.List) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		recommend.Annotate(batch, st.estimate, st.window, method)
+		recommend.AttachLineDiffs(batch, h.lines, query, req.LineType)
+		for i := range batch {
+			if err := w.Write(&batch[i]); err != nil {
+				return err
+			}
+		}
+		total += len(batch)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil && !started {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		// 响应头已经发出，只能中断输出
+		log.Printf("报表导出中断: 已导出%d行, %v", total, err)
+		return
+	}
+	if !started {
+		if err := start(); err != nil {
+			log.Printf("报表导出失败: %v", err)
+			return
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("报表导出失败: %v", err)
+	}
+	log.Printf("报表导出完成: format=%s, 共%d行", format, total)
+}
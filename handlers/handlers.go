@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
 
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/controlline"
 	"gaokao-zhiyuan/database"
-	_ "gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+	"gaokao-zhiyuan/scorerank"
+	"gaokao-zhiyuan/taxonomy"
 
 	"log"
 
@@ -15,15 +21,92 @@ import (
 )
 
 type Handler struct {
-	db *database.ClickHouseDB
+	db    database.AdmissionStore
+	ranks *scorerank.Registry
+	lines *controlline.Set
+	model *recommend.Model
+	tax   *taxonomy.Taxonomy
 }
 
-func NewHandler(db *database.ClickHouseDB) *Handler {
-	return &Handler{db: db}
+func NewHandler(db database.AdmissionStore, ranks *scorerank.Registry, lines *controlline.Set, model *recommend.Model, tax *taxonomy.Taxonomy) *Handler {
+	return &Handler{db: db, ranks: ranks, lines: lines, model: model, tax: tax}
 }
 
-// 查询位次接口 - 使用新的数据源
-// GET /api/rank/get?score=555
+// 解析生源省份配置，省份不支持时返回400
+func resolveProvince(c *gin.Context, province string) (*config.ProvinceProfile, bool) {
+	profile, ok := config.GetProvinceProfile(province)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "不支持的省份: " + province,
+		})
+		return nil, false
+	}
+	return profile, true
+}
+
+// 解析科类，为空时使用省份默认科类，科类不属于该省份时返回400
+func resolveCategory(c *gin.Context, profile *config.ProvinceProfile, category string) (string, bool) {
+	category = profile.ResolveCategory(category)
+	if !profile.HasCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("%s不支持科类: %s", profile.Name, category),
+		})
+		return "", false
+	}
+	return category, true
+}
+
+// 解析省份、科类和批次，科类和批次为空时使用省份默认值，不支持时返回400
+func resolveScope(c *gin.Context, province, category, batch string) (*config.ProvinceProfile, string, string, bool) {
+	profile, ok := resolveProvince(c, province)
+	if !ok {
+		return nil, "", "", false
+	}
+	category, ok = resolveCategory(c, profile, category)
+	if !ok {
+		return nil, "", "", false
+	}
+	batch, ok = resolveBatch(c, profile, batch)
+	if !ok {
+		return nil, "", "", false
+	}
+	return profile, category, batch, true
+}
+
+// 解析批次，为空时使用省份默认批次，批次不属于该省份时返回400
+func resolveBatch(c *gin.Context, profile *config.ProvinceProfile, batch string) (string, bool) {
+	batch = profile.ResolveBatch(batch)
+	if !profile.HasBatch(batch) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("%s不支持批次: %s", profile.Name, batch),
+		})
+		return "", false
+	}
+	return batch, true
+}
+
+// 解析年份参数，为空时使用 defaultYear
+func parseYear(c *gin.Context, name string, defaultYear int) (int, bool) {
+	yearStr := c.Query(name)
+	if yearStr == "" {
+		return defaultYear, true
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 2100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  name + "参数格式错误",
+		})
+		return 0, false
+	}
+	return year, true
+}
+
+// 查询位次接口 - 使用官方一分一段表
+// GET /api/rank/get?score=555&province=湖北&subject_category=物理&year=2024
 func (h *Handler) GetRank(c *gin.Context) {
 	scoreStr := c.Query("score")
 	if scoreStr == "" {
@@ -43,13 +126,24 @@ func (h *Handler) GetRank(c *gin.Context) {
 		return
 	}
 
-	// 获取科目类别参数，默认为物理
-	subjectCategory := c.DefaultQuery("subject_category", "物理")
+	// 获取省份和科目类别参数，默认使用省份的默认科类
+	profile, ok := resolveProvince(c, c.Query("province"))
+	if !ok {
+		return
+	}
+	subjectCategory, ok := resolveCategory(c, profile, c.Query("subject_category"))
+	if !ok {
+		return
+	}
+
+	year, ok := parseYear(c, "year", models.ReferenceAdmissionYear)
+	if !ok {
+		return
+	}
 
-	// 使用新的查询方法
-	rank, err := h.db.QueryRankByScoreNew(score, subjectCategory)
+	pos, err := h.ranks.Lookup(profile.Name, year, subjectCategory, int(score))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 1,
 			"msg":  "查询失败: " + err.Error(),
 		})
@@ -57,11 +151,16 @@ func (h *Handler) GetRank(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"code":  0,
-		"msg":   "success",
-		"rank":  rank,
-		"year":  2024,
-		"score": score,
+		"code":             0,
+		"msg":              "success",
+		"rank":             pos.Rank,
+		"num":              pos.Num,
+		"best_rank":        pos.BestRank,
+		"worst_rank":       pos.WorstRank,
+		"year":             year,
+		"score":            score,
+		"province":         profile.Name,
+		"subject_category": subjectCategory,
 	})
 }
 
@@ -73,7 +172,7 @@ func (h *Handler) QueryRank(c *gin.Context) {
 		Year        int      `json:"year"`
 		Score       int64    `json:"score"`
 		SubjectType string   `json:"subject_type"`
-		ClassDemand []string `json:"class_demand"`
+		ClassDemand []string `json:"class_demand"` // 兼容旧参数，全省位次与选科无关
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -85,22 +184,21 @@ func (h *Handler) QueryRank(c *gin.Context) {
 	}
 
 	// 参数验证
-	if req.Province == "" {
-		req.Province = "湖北"
+	profile, ok := resolveProvince(c, req.Province)
+	if !ok {
+		return
 	}
+	req.Province = profile.Name
 	if req.Year == 0 {
-		req.Year = 2024
+		req.Year = models.ReferenceAdmissionYear
 	}
-	if req.SubjectType == "" {
-		req.SubjectType = "物理"
-	}
-	if len(req.ClassDemand) == 0 {
-		req.ClassDemand = []string{"物", "化", "生"}
+	if req.SubjectType, ok = resolveCategory(c, profile, req.SubjectType); !ok {
+		return
 	}
 
-	rank, err := h.db.QueryRankByScore(req.Province, req.Year, float64(req.Score), req.SubjectType, req.ClassDemand)
+	pos, err := h.ranks.Lookup(req.Province, req.Year, req.SubjectType, int(req.Score))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 1,
 			"msg":  "查询失败: " + err.Error(),
 		})
@@ -110,7 +208,10 @@ func (h *Handler) QueryRank(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code":         0,
 		"msg":          "success",
-		"rank":         rank,
+		"rank":         pos.Rank,
+		"num":          pos.Num,
+		"best_rank":    pos.BestRank,
+		"worst_rank":   pos.WorstRank,
 		"year":         req.Year,
 		"province":     req.Province,
 		"subject_type": req.SubjectType,
@@ -119,113 +220,402 @@ func (h *Handler) QueryRank(c *gin.Context) {
 }
 
 // 报表查询接口 - 新版本
-// GET /api/report/get?rank=333&class_first_choise=物理&class_optional_choise=["化学","生物"]&province=湖北&page=1&page_size=10&college_location=["湖北"]&interest=["理科","工科"]&strategy=0&fuzzy_subject_category=物理
+// GET /api/report/get?rank=333&class_first_choise=物理&class_optional_choise=["化学","生物"]&province=湖北&batch=本科批&page=1&page_size=10&college_location=["湖北"]&interest=["理科","工科"]&major_category=["0809"]&strategy=0&fuzzy_subject_category=物理&year=2024&history_years=3
+// 也可以用 score+exam_year 代替rank，例如 score=600&exam_year=2025 表示2025年考600分
 func (h *Handler) GetReport(c *gin.Context) {
 	// 获取参数
 	rankStr := c.Query("rank")
-	classFirstChoice := c.Query("class_first_choise")
-	classOptionalChoiceStr := c.Query("class_optional_choise")
-	province := c.Query("province")
+	scoreStr := c.Query("score")
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "10")
-	collegeLocationStr := c.Query("college_location")
-	interestStr := c.Query("interest")
 	strategyStr := c.DefaultQuery("strategy", "0")
-	fuzzySubjectCategory := c.Query("fuzzy_subject_category")
+	historyYearsStr := c.DefaultQuery("history_years", "3")
+
+	req := reportRequest{
+		ClassFirstChoice:     c.Query("class_first_choise"),
+		Province:             c.Query("province"),
+		Batch:                c.Query("batch"),
+		FuzzySubjectCategory: c.Query("fuzzy_subject_category"),
+		Method:               c.Query("method"),
+		RankWindows:          c.Query("rank_windows"),
+		LineDiffWindows:      c.Query("line_diff_windows"),
+		LineType:             c.Query("line_type"),
+		GroupBy:              c.Query("group_by"),
+	}
 
 	// 参数验证
-	if rankStr == "" {
+	if rankStr == "" && scoreStr == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 1,
-			"msg":  "缺少rank参数",
+			"msg":  "缺少rank或score参数",
 		})
 		return
 	}
 
-	rank, err := strconv.ParseInt(rankStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code": 1,
-			"msg":  "rank参数格式错误",
-		})
+	var err error
+	if rankStr != "" {
+		req.Rank, err = strconv.ParseInt(rankStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "rank参数格式错误",
+			})
+			return
+		}
+	} else {
+		req.Score, err = strconv.ParseInt(scoreStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "score参数格式错误",
+			})
+			return
+		}
+	}
+
+	// 非法的分页和策略参数使用默认值
+	req.Page, _ = strconv.ParseInt(pageStr, 10, 64)
+	req.PageSize, _ = strconv.ParseInt(pageSizeStr, 10, 64)
+	if req.Strategy, err = strconv.Atoi(strategyStr); err != nil {
+		req.Strategy = 0
+	}
+	req.HistoryYears, _ = strconv.Atoi(historyYearsStr)
+
+	// 参考年份及考生高考年份
+	var ok bool
+	if req.Year, ok = parseYear(c, "year", models.ReferenceAdmissionYear); !ok {
+		return
+	}
+	if req.ExamYear, ok = parseYear(c, "exam_year", req.Year); !ok {
 		return
 	}
 
-	page, err := strconv.ParseInt(pageStr, 10, 64)
-	if err != nil || page < 1 {
-		page = 1
+	// 解析JSON数组参数
+	req.ClassOptionalChoice = parseJSONList(c, "class_optional_choise")
+	req.CollegeLocation = parseJSONList(c, "college_location")
+	req.Interest = parseJSONList(c, "interest")
+	req.MajorCategory = parseJSONList(c, "major_category")
+	req.Dislikes = parseJSONList(c, "dislikes")
+
+	h.runReport(c, &req)
+}
+
+// 解析JSON数组字符串参数，格式错误时忽略该参数
+func parseJSONList(c *gin.Context, name string) []string {
+	value := c.Query(name)
+	if value == "" {
+		return nil
 	}
+	var list []string
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		log.Printf("解析%s参数失败: %v", name, err)
+		return []string{}
+	}
+	return list
+}
 
-	pageSize, err := strconv.ParseInt(pageSizeStr, 10, 64)
-	if err != nil || pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+// 解析JSON数组字符串参数，格式错误时返回400
+func bindJSONList(c *gin.Context, name string) ([]string, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	var list []string
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  name + "参数格式错误，应为JSON数组",
+		})
+		return nil, false
 	}
+	return list, true
+}
 
-	strategy, err := strconv.Atoi(strategyStr)
+// 解析冲稳保划分方法，返回按请求覆盖了默认窗口的模型副本
+func (h *Handler) resolveMethod(c *gin.Context, methodStr, rankWindowsStr, lineDiffWindowsStr, lineType string) (recommend.Method, *recommend.Model, bool) {
+	method, err := recommend.ParseMethod(methodStr)
 	if err != nil {
-		strategy = 0
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  err.Error(),
+		})
+		return "", nil, false
 	}
-
-	// SQL注入防护：fuzzy_subject_category参数校验
-	if fuzzySubjectCategory != "" {
-		// 只允许字母、数字、中文和基本标点符号，防止SQL注入
-		validPattern := regexp.MustCompile(`^[a-zA-Z0-9\p{Han}\s\-_()（）]+$`)
-		if !validPattern.MatchString(fuzzySubjectCategory) {
+	model := *h.model
+	if rankWindowsStr != "" {
+		if model.RankWindows, err = recommend.ParseRankWindows(rankWindowsStr); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code": 1,
-				"msg":  "fuzzy_subject_category参数包含非法字符",
+				"msg":  "rank_windows参数错误: " + err.Error(),
 			})
-			return
+			return "", nil, false
 		}
-		// 限制参数长度，防止过长的输入
-		if len(fuzzySubjectCategory) > 50 {
+	}
+	if lineDiffWindowsStr != "" {
+		if model.LineDiffWindows, err = recommend.ParseLineDiffWindows(lineDiffWindowsStr); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code": 1,
-				"msg":  "fuzzy_subject_category参数长度不能超过50个字符",
+				"msg":  "line_diff_windows参数错误: " + err.Error(),
 			})
-			return
+			return "", nil, false
 		}
 	}
+	if lineType != controlline.TypeBatch && lineType != controlline.TypeSpecial {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "line_type参数错误，可选 批次线、特控线",
+		})
+		return "", nil, false
+	}
+	return method, &model, true
+}
 
-	// 解析JSON数组参数
-	var classOptionalChoice []string
-	if classOptionalChoiceStr != "" {
-		if err := json.Unmarshal([]byte(classOptionalChoiceStr), &classOptionalChoice); err != nil {
-			log.Printf("解析class_optional_choise参数失败: %v", err)
-			classOptionalChoice = []string{}
-		}
+// SQL注入防护：fuzzy_subject_category参数校验
+func validateFuzzySubjectCategory(c *gin.Context, fuzzySubjectCategory string) bool {
+	return validateKeyword(c, "fuzzy_subject_category", fuzzySubjectCategory)
+}
+
+// 校验专业名称关键词参数 name
+func validateKeyword(c *gin.Context, name, keyword string) bool {
+	if keyword == "" {
+		return true
+	}
+	// 只允许字母、数字、中文和基本标点符号，防止SQL注入
+	validPattern := regexp.MustCompile(`^[a-zA-Z0-9\p{Han}\s\-_()（）]+$`)
+	if !validPattern.MatchString(keyword) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  name + "参数包含非法字符",
+		})
+		return false
+	}
+	// 限制参数长度，防止过长的输入
+	if len(keyword) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  name + "参数长度不能超过50个字符",
+		})
+		return false
 	}
+	return true
+}
+
+// 考生的位次、分数及冲稳保划分
+type student struct {
+	score    int64
+	estimate *recommend.Estimate
+	window   recommend.Window
+}
 
-	var collegeLocation []string
-	if collegeLocationStr != "" {
-		if err := json.Unmarshal([]byte(collegeLocationStr), &collegeLocation); err != nil {
-			log.Printf("解析college_location参数失败: %v", err)
-			collegeLocation = []string{}
+// 补全考生位次和分数，估算录取概率并按划分方法生成冲稳保窗口。
+// q.Rank 为0时按 score 换算位次，换算后的位次写回 q.Rank
+func (h *Handler) resolveStudent(c *gin.Context, model *recommend.Model, method recommend.Method, q *models.ReportQuery, lineType string, score int64) (*student, bool) {
+	// 只给出分数时，先从高考年份的一分一段表换算位次；只给出位次时，取位次所在分数段的最低分作为考生分数
+	if q.Rank == 0 {
+		pos, err := h.ranks.Lookup(q.Province, q.ExamYear, q.ClassFirstChoice, int(score))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "分数换算位次失败: " + err.Error(),
+			})
+			return nil, false
 		}
+		q.Rank = int64(pos.Rank)
+	} else {
+		band, err := h.ranks.ScoreByRank(q.Province, q.ExamYear, q.ClassFirstChoice, int(q.Rank))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "位次换算分数失败: " + err.Error(),
+			})
+			return nil, false
+		}
+		score = int64(band.ScoreLow)
+	}
+
+	// 按参考年份一分一段表的分数段密度估算录取概率
+	estimate, err := model.Estimate(q.Province, q.Year, q.ClassFirstChoice, int(q.Rank))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "录取概率估算失败: " + err.Error(),
+		})
+		return nil, false
 	}
 
-	var interest []string
-	if interestStr != "" {
-		if err := json.Unmarshal([]byte(interestStr), &interest); err != nil {
-			log.Printf("解析interest参数失败: %v", err)
-			interest = []string{}
+	st := &student{score: score, estimate: estimate}
+	switch method {
+	case recommend.MethodScore:
+		st.window = estimate
+	case recommend.MethodRank:
+		st.window = model.RankWindows.For(int(q.Rank))
+	case recommend.MethodLineDiff:
+		lineDiff, err := model.LineDiff(q.Province, q.ExamYear, q.Year, q.ClassFirstChoice, q.Batch, lineType, int(score))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "线差计算失败: " + err.Error(),
+			})
+			return nil, false
 		}
+		st.window = lineDiff
+	}
+	return st, true
+}
+
+// 控制线查询接口
+// GET /api/v1/control_lines?province=湖北&year=2024
+func (h *Handler) GetControlLines(c *gin.Context) {
+	profile, ok := resolveProvince(c, c.Query("province"))
+	if !ok {
+		return
+	}
+	year, ok := parseYear(c, "year", 0)
+	if !ok {
+		return
+	}
+
+	lines := h.lines.Lines(profile.Name, year)
+	if lines == nil {
+		lines = []controlline.Line{}
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": lines,
+	})
+}
 
-	log.Printf("报表查询请求: rank=%d, classFirstChoice=%s, classOptionalChoice=%v, province=%s, page=%d, pageSize=%d, collegeLocation=%v, interest=%v, strategy=%d, fuzzySubjectCategory=%s",
-		rank, classFirstChoice, classOptionalChoice, province, page, pageSize, collegeLocation, interest, strategy, fuzzySubjectCategory)
+// 位次换算分数接口
+// GET /api/v1/score_by_rank?rank=45051&province=湖北&subject_category=物理&year=2024
+func (h *Handler) GetScoreByRank(c *gin.Context) {
+	rankStr := c.Query("rank")
+	if rankStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少rank参数",
+		})
+		return
+	}
 
-	// 使用新的查询方法，传递fuzzy_subject_category参数
-	result, err := h.db.GetReportDataNew(rank, classFirstChoice, classOptionalChoice, province, page, pageSize, collegeLocation, interest, strategy, fuzzySubjectCategory)
+	rank, err := strconv.Atoi(rankStr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "rank参数格式错误",
+		})
+		return
+	}
+
+	profile, ok := resolveProvince(c, c.Query("province"))
+	if !ok {
+		return
+	}
+	subjectCategory, ok := resolveCategory(c, profile, c.Query("subject_category"))
+	if !ok {
+		return
+	}
+	year, ok := parseYear(c, "year", models.ReferenceAdmissionYear)
+	if !ok {
+		return
+	}
+
+	band, err := h.ranks.ScoreByRank(profile.Name, year, subjectCategory, rank)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"code": 1,
 			"msg":  "查询失败: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, gin.H{
+		"code":             0,
+		"msg":              "success",
+		"rank":             band.Rank,
+		"score":            band.ScoreLow,
+		"score_high":       band.ScoreHigh,
+		"num":              band.Num,
+		"best_rank":        band.BestRank,
+		"worst_rank":       band.WorstRank,
+		"year":             year,
+		"province":         profile.Name,
+		"subject_category": subjectCategory,
+	})
+}
+
+// 等位分换算接口
+// GET /api/v1/equivalent_score?score=600&from_year=2025&to_year=2024&province=湖北&subject_category=物理
+func (h *Handler) GetEquivalentScore(c *gin.Context) {
+	scoreStr := c.Query("score")
+	if scoreStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少score参数",
+		})
+		return
+	}
+
+	score, err := strconv.Atoi(scoreStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "score参数格式错误",
+		})
+		return
+	}
+
+	if c.Query("from_year") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少from_year参数",
+		})
+		return
+	}
+	fromYear, ok := parseYear(c, "from_year", 0)
+	if !ok {
+		return
+	}
+	toYear, ok := parseYear(c, "to_year", models.ReferenceAdmissionYear)
+	if !ok {
+		return
+	}
+
+	profile, ok := resolveProvince(c, c.Query("province"))
+	if !ok {
+		return
+	}
+	subjectCategory, ok := resolveCategory(c, profile, c.Query("subject_category"))
+	if !ok {
+		return
+	}
+
+	eq, err := h.ranks.EquivalentScore(profile.Name, subjectCategory, score, fromYear, toYear)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "换算失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":             0,
+		"msg":              "success",
+		"data":             eq,
+		"province":         profile.Name,
+		"subject_category": subjectCategory,
+	})
+}
+
+// 省份配置接口
+// GET /api/v1/provinces
+func (h *Handler) GetProvinces(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": config.ProvinceProfiles(),
+	})
 }
 
 // 健康检查
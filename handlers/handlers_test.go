@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gaokao-zhiyuan/controlline"
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+	"gaokao-zhiyuan/scorerank"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// 测试用的处理器：湖北2024年物理类一分一段表共1000人，录取数据由 rows 给出
+func newTestHandler(t *testing.T, rows []models.AdmissionHubeiWide) *Handler {
+	t.Helper()
+	ranks := scorerank.NewRegistry()
+	err := ranks.Add(&scorerank.Table{
+		Province: "湖北",
+		Year:     models.ReferenceAdmissionYear,
+		Category: "物理",
+		Segments: []scorerank.Segment{
+			{ScoreLow: 600, ScoreHigh: 750, Num: 100, Accumulate: 100},
+			{ScoreLow: 550, ScoreHigh: 599, Num: 300, Accumulate: 400},
+			{ScoreLow: 500, ScoreHigh: 549, Num: 600, Accumulate: 1000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := controlline.Parse(strings.NewReader(`{"data":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := recommend.Options{SigmaPoints: 5}
+	if opts.Bands, err = recommend.ParseBands("0.15,0.5,0.85"); err != nil {
+		t.Fatal(err)
+	}
+	if opts.RankWindows, err = recommend.ParseRankWindows("-20%,0%,15%,50%"); err != nil {
+		t.Fatal(err)
+	}
+	if opts.LineDiffWindows, err = recommend.ParseLineDiffWindows("-20,-3,5,20"); err != nil {
+		t.Fatal(err)
+	}
+	if opts.TierRatio, err = recommend.ParseTierRatio("3:4:3"); err != nil {
+		t.Fatal(err)
+	}
+	model := recommend.NewModel(ranks, lines, opts)
+	db := database.NewMemoryDBFromRows(rows, nil, ranks)
+	return NewHandler(db, ranks, lines, model, nil)
+}
+
+func postJSON(h gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+	return postJSONTo(h, "/", body)
+}
+
+func postJSONTo(h gin.HandlerFunc, target, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h(c)
+	return w
+}
+
+func TestPostReportRankOutOfRange(t *testing.T) {
+	h := newTestHandler(t, nil)
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		msg    string
+	}{
+		{"位次超出一分一段表", `{"rank":5000,"class_first_choise":"物理"}`, http.StatusBadRequest, "位次换算分数失败"},
+		{"位次在表内", `{"rank":300,"class_first_choise":"物理"}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(h.PostReport, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.status, w.Body.String())
+			}
+			var resp struct {
+				Code int    `json:"code"`
+				Msg  string `json:"msg"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(resp.Msg, tt.msg) {
+				t.Errorf("msg = %q, want prefix %q", resp.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+// xlsx导出不受分页参数限制，包含全部符合条件的报表行
+func TestPostReportXLSXAllRows(t *testing.T) {
+	var rows []models.AdmissionHubeiWide
+	for i := 0; i < 15; i++ {
+		rows = append(rows, models.AdmissionHubeiWide{
+			ID: uint32(i + 1), SourceProvince: "湖北", SubjectCategory: "物理", AdmissionBatch: "本科批",
+			SchoolCode: fmt.Sprintf("1%04d", i), SchoolName: "测试大学", MajorGroupCode: "01",
+			MajorCode: "080901", MajorName: "计算机科学与技术",
+			MinScore2024: uint16(560 + i), MinRank2024: uint32(300 + i*10),
+		})
+	}
+	h := newTestHandler(t, rows)
+	body := `{"score":570,"class_first_choise":"物理","strategy":3,"page_size":5}`
+
+	var resp models.Response
+	if err := json.Unmarshal(postJSON(h.PostReport, body).Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	total := resp.Data.Conf.TotalNumber
+	if len(resp.Data.List) != 5 || total <= 5 {
+		t.Fatalf("list = %d, total = %d, want one page of 5 out of more", len(resp.Data.List), total)
+	}
+
+	w := postJSONTo(h.PostReport, "/?format=xlsx", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	f, err := excelize.OpenReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	details, err := f.GetRows("专业明细")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := int64(len(details) - 1); got != total {
+		t.Errorf("detail rows = %d, want %d", got, total)
+	}
+}
+
+func TestGetMajorParams(t *testing.T) {
+	h := newTestHandler(t, nil)
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		msg    string
+	}{
+		{"院校标签格式错误", "school_tags=211", http.StatusBadRequest, "school_tags参数格式错误"},
+		{"院校城市格式错误", `school_city=["武汉"`, http.StatusBadRequest, "school_city参数格式错误"},
+		{"位次超出一分一段表", "rank=5000", http.StatusBadRequest, "位次换算分数失败"},
+		{"高考年份没有一分一段表", "rank=300&exam_year=2023", http.StatusBadRequest, "位次换算分数失败"},
+		{"分数超出一分一段表", "score=400", http.StatusBadRequest, "分数换算位次失败"},
+		{"位次在表内", `rank=300&school_tags=["211"]`, http.StatusOK, "success"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/?class_first_choise=物理&"+tt.query, nil)
+			c.Params = gin.Params{{Key: "major_code", Value: "080901"}}
+			h.GetMajor(c)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.status, w.Body.String())
+			}
+			var resp struct {
+				Msg string `json:"msg"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(resp.Msg, tt.msg) {
+				t.Errorf("msg = %q, want prefix %q", resp.Msg, tt.msg)
+			}
+		})
+	}
+}
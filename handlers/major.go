@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 专业搜索接口，按专业名称关键词查找专业及开设院校数
+// GET /api/v1/majors?keyword=临床医学&class_first_choise=物理
+func (h *Handler) SearchMajors(c *gin.Context) {
+	keyword := c.Query("keyword")
+	if keyword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少keyword参数",
+		})
+		return
+	}
+	if !validateKeyword(c, "keyword", keyword) {
+		return
+	}
+	profile, category, batch, ok := resolveScope(c, c.Query("province"), c.Query("class_first_choise"), c.Query("batch"))
+	if !ok {
+		return
+	}
+
+	rows, err := h.db.ListAdmissions(&models.ReportQuery{
+		Year:                 models.ReferenceAdmissionYear,
+		Province:             profile.Name,
+		ClassFirstChoice:     category,
+		Batch:                batch,
+		FuzzySubjectCategory: keyword,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": recommend.SummarizeMajors(rows),
+	})
+}
+
+// 专业详情接口，返回开设该专业的全部院校专业组，可按院校筛选、排序，
+// 传入位次或分数时返回考生位次在录取位次分布中的位置
+// GET /api/v1/majors/100201K?class_first_choise=物理&rank=6000&sort=rank
+func (h *Handler) GetMajor(c *gin.Context) {
+	majorCode := c.Param("major_code")
+	profile, category, batch, ok := resolveScope(c, c.Query("province"), c.Query("class_first_choise"), c.Query("batch"))
+	if !ok {
+		return
+	}
+
+	// JSON数组参数，格式错误时返回400
+	lists := make(map[string][]string)
+	for _, name := range []string{"class_optional_choise", "college_location", "school_tags", "school_level", "school_city"} {
+		if lists[name], ok = bindJSONList(c, name); !ok {
+			return
+		}
+	}
+	examYear, ok := parseYear(c, "exam_year", models.ReferenceAdmissionYear)
+	if !ok {
+		return
+	}
+
+	query := &models.ReportQuery{
+		Year:                models.ReferenceAdmissionYear,
+		ExamYear:            examYear,
+		Province:            profile.Name,
+		ClassFirstChoice:    category,
+		ClassOptionalChoice: lists["class_optional_choise"],
+		Batch:               batch,
+		CollegeLocation:     lists["college_location"],
+		MajorCode:           majorCode,
+		Filters: models.ReportFilters{
+			SchoolTags:  models.StringFilter{Include: lists["school_tags"]},
+			SchoolLevel: models.StringFilter{Include: lists["school_level"]},
+			SchoolCity:  models.StringFilter{Include: lists["school_city"]},
+		},
+	}
+	if s := c.Query("tuition_max"); s != "" {
+		max, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || max < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "tuition_max参数错误",
+			})
+			return
+		}
+		query.Filters.TuitionFee.Max = &max
+	}
+
+	// 考生位次，与报表查询相同：位次和分数都按高考年份的一分一段表换算和校验
+	var score int64
+	if s := c.Query("rank"); s != "" {
+		rank, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || rank <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "rank参数格式错误",
+			})
+			return
+		}
+		query.Rank = rank
+	} else if s := c.Query("score"); s != "" {
+		var err error
+		if score, err = strconv.ParseInt(s, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "score参数格式错误",
+			})
+			return
+		}
+	}
+	if query.Rank > 0 || c.Query("score") != "" {
+		if _, ok := h.resolveStudent(c, h.model, recommend.MethodRank, query, "", score); !ok {
+			return
+		}
+	}
+
+	rows, err := h.db.ListAdmissions(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+
+	offerings := database.BuildMajorOfferings(h.ranks, rows, profile.Name, category)
+	if err := recommend.SortOfferings(offerings, c.Query("sort"), c.Query("order")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  err.Error(),
+		})
+		return
+	}
+
+	detail := &models.MajorDetail{
+		MajorCode:       majorCode,
+		SubjectCategory: category,
+		Batch:           batch,
+		Total:           len(offerings),
+		Offerings:       offerings,
+	}
+	if len(rows) > 0 {
+		detail.MajorName = rows[0].MajorName
+	}
+	if query.Rank > 0 {
+		detail.Student = recommend.LocateStudent(offerings, query.Rank)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": detail,
+	})
+}
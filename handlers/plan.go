@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gaokao-zhiyuan/controlline"
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/export"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 志愿表生成请求，考生参数与报表查询接口一致
+type planRequest struct {
+	Rank                 int64    `json:"rank"`
+	Score                int64    `json:"score"`
+	Province             string   `json:"province"`
+	ClassFirstChoice     string   `json:"class_first_choise"`
+	ClassOptionalChoice  []string `json:"class_optional_choise"`
+	Batch                string   `json:"batch"`
+	Year                 int      `json:"year"`
+	ExamYear             int      `json:"exam_year"`
+	CollegeLocation      []string `json:"college_location"`
+	Interest             []string `json:"interest"`
+	MajorCategory        []string `json:"major_category"`
+	FuzzySubjectCategory string   `json:"fuzzy_subject_category"`
+	Method               string   `json:"method"`
+	RankWindows          string   `json:"rank_windows"`
+	LineDiffWindows      string   `json:"line_diff_windows"`
+	LineType             string   `json:"line_type"`
+	TierRatio            string   `json:"tier_ratio"`            // 冲稳保比例，如 3:4:3，默认使用配置
+	Slots                int      `json:"slots"`                 // 志愿数，默认使用省份批次的志愿数上限
+	MaxGroupsPerSchool   int      `json:"max_groups_per_school"` // 同一院校最多填报的专业组数，0为不限
+}
+
+// 志愿表生成接口
+// POST /api/v1/plan/generate
+// {"rank":6000,"class_first_choise":"物理","class_optional_choise":["化学","生物"],"tier_ratio":"3:4:3"}
+func (h *Handler) GeneratePlan(c *gin.Context) {
+	format, ok := parseFormat(c)
+	if !ok {
+		return
+	}
+
+	var req planRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Rank <= 0 && req.Score <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少rank或score参数",
+		})
+		return
+	}
+
+	// 省份、科类和批次，为空时使用省份默认值
+	profile, category, batch, ok := resolveScope(c, req.Province, req.ClassFirstChoice, req.Batch)
+	if !ok {
+		return
+	}
+
+	// 志愿数默认取省份批次的上限，且不能超过上限
+	slots := req.Slots
+	limit, hasLimit := profile.VolunteerLimit(batch)
+	if slots == 0 {
+		if !hasLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  fmt.Sprintf("%s%s没有配置志愿数上限，需要指定slots参数", profile.Name, batch),
+			})
+			return
+		}
+		slots = limit
+	}
+	if slots < 1 || (hasLimit && slots > limit) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("slots参数错误，%s%s最多填报%d个志愿", profile.Name, batch, limit),
+		})
+		return
+	}
+	if req.MaxGroupsPerSchool < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "max_groups_per_school参数错误",
+		})
+		return
+	}
+
+	if req.Year == 0 {
+		req.Year = models.ReferenceAdmissionYear
+	}
+	if req.ExamYear == 0 {
+		req.ExamYear = req.Year
+	}
+	if req.LineType == "" {
+		req.LineType = controlline.TypeBatch
+	}
+	method, model, ok := h.resolveMethod(c, req.Method, req.RankWindows, req.LineDiffWindows, req.LineType)
+	if !ok {
+		return
+	}
+	ratio := model.TierRatio
+	if req.TierRatio != "" {
+		var err error
+		if ratio, err = recommend.ParseTierRatio(req.TierRatio); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "tier_ratio参数错误: " + err.Error(),
+			})
+			return
+		}
+	}
+	if !validateFuzzySubjectCategory(c, req.FuzzySubjectCategory) {
+		return
+	}
+
+	log.Printf("志愿表生成请求: %+v", req)
+
+	// 再选科目不参与查询条件，由生成志愿表时按专业组整体判断选科是否符合
+	query := &models.ReportQuery{
+		Rank:                 req.Rank,
+		Year:                 req.Year,
+		ExamYear:             req.ExamYear,
+		Province:             profile.Name,
+		ClassFirstChoice:     category,
+		Batch:                batch,
+		CollegeLocation:      req.CollegeLocation,
+		FuzzySubjectCategory: req.FuzzySubjectCategory,
+	}
+	if !h.resolveMajorFilters(c, query, req.Interest, req.MajorCategory) {
+		return
+	}
+	st, ok := h.resolveStudent(c, model, method, query, req.LineType, req.Score)
+	if !ok {
+		return
+	}
+	// 冲稳保混合范围内的全部录取数据
+	st.window.Apply(query, -1)
+
+	rows, err := h.db.ListAdmissions(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 专业组内被筛选条件排除的专业也要满足选科要求，查询候选专业组的全部专业
+	var refs []models.MajorGroupRef
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		key := row.SchoolCode + "|" + row.MajorGroupCode
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, models.MajorGroupRef{CollegeCode: row.SchoolCode, MajorGroupCode: row.MajorGroupCode})
+		}
+	}
+	members, err := h.db.GetGroupMajors(query.Province, query.ClassFirstChoice, query.Batch, refs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询专业组专业失败: " + err.Error(),
+		})
+		return
+	}
+
+	plan := recommend.BuildPlan(rows, st.estimate, st.window, method, recommend.PlanOptions{
+		Year:               req.Year,
+		Slots:              slots,
+		Ratio:              ratio,
+		MaxGroupsPerSchool: req.MaxGroupsPerSchool,
+		Eligible: func(row *models.AdmissionHubeiWide) bool {
+			return database.SubjectEligible(row, category, req.ClassOptionalChoice)
+		},
+		GroupMajors: members,
+	})
+	plan.Score = st.score
+
+	if format == formatXLSX {
+		var cs criteria
+		cs.addStudent(query, st.score, string(method))
+		cs.addList("再选科目", req.ClassOptionalChoice)
+		cs.addList("兴趣方向", req.Interest)
+		cs.addList("专业类", req.MajorCategory)
+		cs.add("冲稳保比例", plan.TierRatio)
+		cs.add("志愿数", strconv.Itoa(plan.Slots))
+		if req.MaxGroupsPerSchool > 0 {
+			cs.add("同一院校最多专业组数", strconv.Itoa(req.MaxGroupsPerSchool))
+		}
+		f, err := export.PlanWorkbook(plan, req.Year, cs)
+		writeWorkbook(c, f, err, "plan.xlsx")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": plan,
+	})
+}
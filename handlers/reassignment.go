@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 一次最多分析的院校专业组数
+const maxReassignmentGroups = 45
+
+// 考生分数换算到2024年的等位分，用于与 major_min_score_2024 比较
+func (h *Handler) referenceScore(province, category string, score int64, examYear int) (int, error) {
+	if examYear == models.ReferenceAdmissionYear {
+		return int(score), nil
+	}
+	eq, err := h.ranks.EquivalentScore(province, category, int(score), examYear, models.ReferenceAdmissionYear)
+	if err != nil {
+		return 0, err
+	}
+	return eq.EquivalentScore, nil
+}
+
+// 为报表中的院校专业组附加服从调剂风险，查询失败或无法换算等位分时不附加
+func (h *Handler) attachReassignment(groups []models.GroupItem, q *models.ReportQuery, score int64, dislikes []string) {
+	studentScore, err := h.referenceScore(q.Province, q.ClassFirstChoice, score, q.ExamYear)
+	if err != nil {
+		log.Printf("换算调剂风险等位分失败: %v", err)
+		return
+	}
+	refs := make([]models.MajorGroupRef, len(groups))
+	for i, g := range groups {
+		refs[i] = models.MajorGroupRef{CollegeCode: g.CollegeCode, MajorGroupCode: g.MajorGroupCode}
+	}
+	rows, err := h.db.GetGroupMajors(q.Province, q.ClassFirstChoice, q.Batch, refs)
+	if err != nil {
+		log.Printf("查询专业组专业失败: %v", err)
+		return
+	}
+	recommend.AttachReassignment(h.tax, groups, rows, studentScore, dislikes)
+}
+
+// 服从调剂风险分析接口
+// POST /api/v1/reassignment/analyze
+// {"rank":6000,"class_first_choise":"物理","dislikes":["医科","护理"],"groups":[{"college_code":"10487","major_group_code":"01"}]}
+func (h *Handler) AnalyzeReassignment(c *gin.Context) {
+	var req struct {
+		Rank             int64                  `json:"rank"`
+		Score            int64                  `json:"score"`
+		ExamYear         int                    `json:"exam_year"`
+		Province         string                 `json:"province"`
+		ClassFirstChoice string                 `json:"class_first_choise"`
+		Batch            string                 `json:"batch"`
+		Dislikes         []string               `json:"dislikes"` // 不愿就读的兴趣方向或专业名称关键词
+		Groups           []models.MajorGroupRef `json:"groups" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	if req.Rank <= 0 && req.Score <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少rank或score参数",
+		})
+		return
+	}
+	if len(req.Groups) == 0 || len(req.Groups) > maxReassignmentGroups {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("groups数量应为1-%d个", maxReassignmentGroups),
+		})
+		return
+	}
+
+	profile, category, batch, ok := resolveScope(c, req.Province, req.ClassFirstChoice, req.Batch)
+	if !ok {
+		return
+	}
+	if req.ExamYear == 0 {
+		req.ExamYear = models.ReferenceAdmissionYear
+	}
+
+	// 只给出位次时，取位次所在分数段的最低分作为考生分数
+	score := req.Score
+	if req.Rank > 0 {
+		band, err := h.ranks.ScoreByRank(profile.Name, req.ExamYear, category, int(req.Rank))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "位次换算分数失败: " + err.Error(),
+			})
+			return
+		}
+		score = int64(band.ScoreLow)
+	}
+	studentScore, err := h.referenceScore(profile.Name, category, score, req.ExamYear)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "等位分换算失败: " + err.Error(),
+		})
+		return
+	}
+
+	rows, err := h.db.GetGroupMajors(profile.Name, category, batch, req.Groups)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+	byGroup := make(map[models.MajorGroupRef][]models.AdmissionHubeiWide)
+	for _, row := range rows {
+		ref := models.MajorGroupRef{CollegeCode: row.SchoolCode, MajorGroupCode: row.MajorGroupCode}
+		byGroup[ref] = append(byGroup[ref], row)
+	}
+
+	type groupResult struct {
+		models.MajorGroupRef
+		CollegeName  string `json:"college_name,omitempty"`
+		LowestPoints uint16 `json:"lowest_points,omitempty"` // 2024年专业组最低分
+		Found        bool   `json:"found"`                   // 是否查到该专业组
+		*models.Reassignment
+	}
+	results := make([]groupResult, 0, len(req.Groups))
+	for _, ref := range req.Groups {
+		groupRows := byGroup[ref]
+		item := groupResult{MajorGroupRef: ref, Found: len(groupRows) > 0}
+		if item.Found {
+			item.CollegeName = groupRows[0].SchoolName
+			item.LowestPoints = groupRows[0].MinScore2024
+		}
+		item.Reassignment = recommend.AnalyzeReassignment(h.tax, groupRows, studentScore, req.Dislikes)
+		results = append(results, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": results,
+	})
+}
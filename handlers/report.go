@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/controlline"
+	"gaokao-zhiyuan/export"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/recommend"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 报表查询参数，GET 接口的查询参数与 POST 接口的请求体字段同名
+type reportRequest struct {
+	Rank                 int64                `json:"rank"`
+	Score                int64                `json:"score"`
+	ClassFirstChoice     string               `json:"class_first_choise"`
+	ClassOptionalChoice  []string             `json:"class_optional_choise"`
+	Province             string               `json:"province"`
+	Batch                string               `json:"batch"`
+	Page                 int64                `json:"page"`
+	PageSize             int64                `json:"page_size"`
+	CollegeLocation      []string             `json:"college_location"`
+	Interest             []string             `json:"interest"`
+	MajorCategory        []string             `json:"major_category"` // 专业类代码或名称，如 0809、计算机类
+	Strategy             int                  `json:"strategy"`
+	FuzzySubjectCategory string               `json:"fuzzy_subject_category"`
+	Year                 int                  `json:"year"`
+	ExamYear             int                  `json:"exam_year"`
+	HistoryYears         int                  `json:"history_years"`
+	Method               string               `json:"method"`
+	RankWindows          string               `json:"rank_windows"`
+	LineDiffWindows      string               `json:"line_diff_windows"`
+	LineType             string               `json:"line_type"`
+	GroupBy              string               `json:"group_by"`
+	Dislikes             []string             `json:"dislikes"`
+	Filters              models.ReportFilters `json:"filters"` // 结构化筛选条件，只有 POST 接口支持
+}
+
+// 报表查询接口 - 结构化筛选条件
+// POST /api/v1/report
+// {"rank":6000,"class_first_choise":"物理","filters":{"school_tags":{"include":["985","211"]},"tuition_fee":{"max":10000}}}
+func (h *Handler) PostReport(c *gin.Context) {
+	req, ok := bindReportRequest(c)
+	if !ok {
+		return
+	}
+	h.runReport(c, req)
+}
+
+// 解析 POST 接口的报表查询请求体
+func bindReportRequest(c *gin.Context) (*reportRequest, bool) {
+	req := reportRequest{Page: 1, PageSize: 10, HistoryYears: 3}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "请求参数错误: " + err.Error(),
+		})
+		return nil, false
+	}
+	if req.Rank <= 0 && req.Score <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "缺少rank或score参数",
+		})
+		return nil, false
+	}
+	if req.Year == 0 {
+		req.Year = models.ReferenceAdmissionYear
+	}
+	if req.ExamYear == 0 {
+		req.ExamYear = req.Year
+	}
+	for name, year := range map[string]int{"year": req.Year, "exam_year": req.ExamYear} {
+		if year < 2000 || year > 2100 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  name + "参数格式错误",
+			})
+			return nil, false
+		}
+	}
+	return &req, true
+}
+
+// 校验结构化筛选条件中的取值
+func validateFilters(c *gin.Context, profile *config.ProvinceProfile, f *models.ReportFilters) bool {
+	fail := func(msg string) bool {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "filters参数错误: " + msg,
+		})
+		return false
+	}
+
+	for _, batch := range append(f.AdmissionBatch.Include, f.AdmissionBatch.Exclude...) {
+		if !profile.HasBatch(batch) {
+			return fail(fmt.Sprintf("%s不支持批次: %s", profile.Name, batch))
+		}
+	}
+	ranges := map[string]models.RangeFilter{"tuition_fee": f.TuitionFee, "study_duration": f.StudyDuration}
+	for name, r := range ranges {
+		if (r.Min != nil && *r.Min < 0) || (r.Max != nil && *r.Max < 0) {
+			return fail(name + "不能为负数")
+		}
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return fail(name + "的min不能大于max")
+		}
+	}
+	return true
+}
+
+// 校验报表查询参数并按冲稳保策略生成查询条件，req 中未填写的参数使用省份默认值
+func (h *Handler) prepareReport(c *gin.Context, req *reportRequest) (*models.ReportQuery, *student, recommend.Method, bool) {
+	// 省份、科类和批次，为空时使用省份默认值
+	profile, classFirstChoice, batch, ok := resolveScope(c, req.Province, req.ClassFirstChoice, req.Batch)
+	if !ok {
+		return nil, nil, "", false
+	}
+	province := profile.Name
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+	if req.HistoryYears < 1 || req.HistoryYears > 10 {
+		req.HistoryYears = 3
+	}
+	if req.LineType == "" {
+		req.LineType = controlline.TypeBatch
+	}
+
+	// 冲稳保划分方法，位次法和线差法可以覆盖默认窗口
+	method, model, ok := h.resolveMethod(c, req.Method, req.RankWindows, req.LineDiffWindows, req.LineType)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	if !validateFuzzySubjectCategory(c, req.FuzzySubjectCategory) {
+		return nil, nil, "", false
+	}
+	// 按院校专业组聚合，与志愿表的填报单位一致
+	if req.GroupBy != "" && req.GroupBy != "major_group" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 1,
+			"msg":  "group_by参数错误，可选 major_group",
+		})
+		return nil, nil, "", false
+	}
+	if !validateFilters(c, profile, &req.Filters) {
+		return nil, nil, "", false
+	}
+
+	log.Printf("报表查询请求: rank=%d, year=%d, classFirstChoice=%s, classOptionalChoice=%v, province=%s, batch=%s, page=%d, pageSize=%d, collegeLocation=%v, interest=%v, majorCategory=%v, strategy=%d, fuzzySubjectCategory=%s, filters=%+v",
+		req.Rank, req.Year, classFirstChoice, req.ClassOptionalChoice, province, batch, req.Page, req.PageSize, req.CollegeLocation, req.Interest, req.MajorCategory, req.Strategy, req.FuzzySubjectCategory, req.Filters)
+
+	query := &models.ReportQuery{
+		Rank:                 req.Rank,
+		Year:                 req.Year,
+		ExamYear:             req.ExamYear,
+		HistoryYears:         req.HistoryYears,
+		Province:             province,
+		ClassFirstChoice:     classFirstChoice,
+		ClassOptionalChoice:  req.ClassOptionalChoice,
+		Batch:                batch,
+		Page:                 req.Page,
+		PageSize:             req.PageSize,
+		CollegeLocation:      req.CollegeLocation,
+		FuzzySubjectCategory: req.FuzzySubjectCategory,
+		GroupByMajorGroup:    req.GroupBy == "major_group",
+		Filters:              req.Filters,
+	}
+	if !h.resolveMajorFilters(c, query, req.Interest, req.MajorCategory) {
+		return nil, nil, "", false
+	}
+
+	// 冲稳保策略按划分方法换算为录取位次或录取分范围
+	st, ok := h.resolveStudent(c, model, method, query, req.LineType, req.Score)
+	if !ok {
+		return nil, nil, "", false
+	}
+	st.window.Apply(query, req.Strategy)
+	return query, st, method, true
+}
+
+// 执行报表查询
+func (h *Handler) runReport(c *gin.Context, req *reportRequest) {
+	format, ok := parseFormat(c)
+	if !ok {
+		return
+	}
+	query, st, method, ok := h.prepareReport(c, req)
+	if !ok {
+		return
+	}
+
+	// xlsx导出全部符合条件的报表行，忽略分页参数
+	if format == formatXLSX {
+		query.Page = 1
+		query.PageSize = maxXLSXReportRows
+	}
+
+	result, err := h.db.GetReportDataNew(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+	recommend.Annotate(result.Data.List, st.estimate, st.window, method)
+	recommend.AttachLineDiffs(result.Data.List, h.lines, query, req.LineType)
+	recommend.AnnotateGroups(result.Data.Groups, st.estimate, st.window, method)
+	recommend.AttachGroupLineDiffs(result.Data.Groups, h.lines, query, req.LineType)
+	if len(result.Data.Groups) > 0 {
+		h.attachReassignment(result.Data.Groups, query, st.score, req.Dislikes)
+	}
+	if diff, err := h.lines.LineDiff(query.Province, req.ExamYear, query.ClassFirstChoice, query.Batch, req.LineType, int(st.score)); err == nil {
+		result.StudentLineDiff = &diff
+	}
+
+	if format == formatXLSX {
+		var cs criteria
+		cs.addStudent(query, st.score, string(method))
+		cs.add("冲稳保策略", strategyName(req.Strategy))
+		cs.addList("兴趣方向", req.Interest)
+		cs.addList("专业类", req.MajorCategory)
+		cs.addFilters(&req.Filters)
+		if query.GroupByMajorGroup {
+			cs.add("聚合方式", "按院校专业组")
+		}
+		if conf := result.Data.Conf; conf != nil {
+			if conf.TotalNumber > maxXLSXReportRows {
+				cs.add("导出条数", fmt.Sprintf("共%d条，只导出前%d条", conf.TotalNumber, maxXLSXReportRows))
+			} else {
+				cs.add("导出条数", fmt.Sprintf("共%d条", conf.TotalNumber))
+			}
+		}
+		f, err := export.ReportWorkbook(result, query.Year, cs)
+		writeWorkbook(c, f, err, "report.xlsx")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
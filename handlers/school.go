@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"gaokao-zhiyuan/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 院校详情接口，返回院校信息及其在考生科类、批次的全部专业组和专业
+// GET /api/v1/schools/10487?class_first_choise=物理
+func (h *Handler) GetSchool(c *gin.Context) {
+	schoolCode := c.Param("school_code")
+
+	profile, category, batch, ok := resolveScope(c, c.Query("province"), c.Query("class_first_choise"), c.Query("batch"))
+	if !ok {
+		return
+	}
+
+	rows, err := h.db.GetSchoolMajors(profile.Name, category, batch, schoolCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": 1,
+			"msg":  "查询失败: " + err.Error(),
+		})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": 1,
+			"msg":  fmt.Sprintf("没有院校 %s 在%s%s%s的招生数据", schoolCode, profile.Name, category, batch),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": database.BuildSchoolDetail(h.ranks, rows, profile.Name, category, batch),
+	})
+}
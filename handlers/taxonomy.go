@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gaokao-zhiyuan/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 将兴趣方向名称换算为 is_* 标签字段，专业类名称或代码换算为专业类代码，
+// 不在专业分类体系中的取值返回400
+func (h *Handler) resolveMajorFilters(c *gin.Context, q *models.ReportQuery, interests, categories []string) bool {
+	for _, name := range interests {
+		interest, ok := h.tax.Interest(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "不支持的兴趣方向: " + name,
+			})
+			return false
+		}
+		q.InterestFlags = append(q.InterestFlags, interest.Flag)
+	}
+	for _, name := range categories {
+		category, ok := h.tax.Category(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": 1,
+				"msg":  "不支持的专业类: " + name,
+			})
+			return false
+		}
+		q.MajorCategories = append(q.MajorCategories, category.Code)
+	}
+	return true
+}
+
+// 专业分类体系接口，返回兴趣方向、学科门类和专业类
+// GET /api/v1/taxonomy
+func (h *Handler) GetTaxonomy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "success",
+		"data": h.tax,
+	})
+}
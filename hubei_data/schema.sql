@@ -0,0 +1,78 @@
+-- 由 models 结构体标签生成（make schema），请勿手动修改。表结构变更见 database/migrations
+
+-- 录取数据宽表
+CREATE TABLE IF NOT EXISTS gaokao2025 (
+	id                       UInt32 COMMENT '记录唯一标识',
+	school_code              String COMMENT '院校代码',
+	school_name              String COMMENT '院校名称',
+	major_code               String COMMENT '专业代码',
+	major_name               String COMMENT '专业名称',
+	major_group_code         String COMMENT '专业组代码',
+	source_province          LowCardinality(String) COMMENT '生源省份',
+	school_province          String COMMENT '院校所在省份',
+	school_city              String COMMENT '院校所在城市',
+	admission_batch          LowCardinality(String) COMMENT '录取批次',
+	subject_category         LowCardinality(String) COMMENT '科类：物理、历史或综合',
+	require_physics          Bool COMMENT '是否要求选择物理',
+	require_chemistry        Bool COMMENT '是否要求选择化学',
+	require_biology          Bool COMMENT '是否要求选择生物',
+	require_politics         Bool COMMENT '是否要求选择政治',
+	require_history          Bool COMMENT '是否要求选择历史',
+	require_geography        Bool COMMENT '是否要求选择地理',
+	subject_requirement_raw  String COMMENT '原始选科限制描述',
+	subject_requirement      Array(Array(String)) COMMENT '选科要求（析取范式，选齐任一组内的科目即满足，空数组为不限），导入时由原始选科限制解析',
+	school_type              String COMMENT '院校类型',
+	school_ownership         Enum8('公办' = 1, '内地与港澳台合作办学' = 2, '中外合作办学' = 3, '民办' = 4, '境外高校独立办学' = 5) COMMENT '公私性质',
+	school_authority         String COMMENT '院校隶属单位',
+	school_level             String COMMENT '院校水平层次',
+	school_tags              String COMMENT '院校标签，如985、211等',
+	education_level          Enum8('本科' = 1, '职业本科' = 2, '专科' = 3) COMMENT '本科/专科',
+	major_description        String COMMENT '专业备注信息',
+	study_duration           UInt8 COMMENT '学制年数',
+	tuition_fee              String COMMENT '学费（元/年）',
+	is_new_major             Bool COMMENT '是否为新增专业',
+	min_score_2024           UInt16 COMMENT '2024年专业组最低录取分数',
+	min_rank_2024            UInt32 COMMENT '2024年专业组最低录取位次',
+	major_min_score_2024     UInt16 COMMENT '2024年专业最低录取分数',
+	enrollment_plan_2024     UInt16 COMMENT '2024年招生计划数',
+	is_science               Bool COMMENT '是否为理科专业',
+	is_engineering           Bool COMMENT '是否为工科专业',
+	is_medical               Bool COMMENT '是否为医科专业',
+	is_economics_mgmt_law    Bool COMMENT '是否为经管法专业',
+	is_liberal_arts          Bool COMMENT '是否为文科专业（非经管法）',
+	is_design_arts           Bool COMMENT '是否为设计与艺术类专业',
+	is_language              Bool COMMENT '是否为语言类专业',
+	enrollment_plan          UInt16 COMMENT '当年招生计划数',
+	major_id                 String COMMENT '专业ID',
+	enrollment_type          LowCardinality(String) COMMENT '招生类型',
+	enrollment_plan_year     UInt16 COMMENT '招生计划年份',
+	major_category           LowCardinality(String) COMMENT '专业类别',
+	admission_num_2024       UInt16 COMMENT '2024年专业组录取人数',
+	major_min_rank_2024      UInt32 COMMENT '2024年专业最低录取位次',
+	major_avg_score_2024     UInt16 COMMENT '2024年专业平均分',
+	major_avg_rank_2024      UInt32 COMMENT '2024年专业平均位次',
+	major_max_score_2024     UInt16 COMMENT '2024年专业最高分',
+	major_max_rank_2024      UInt32 COMMENT '2024年专业最高位次',
+	major_admission_num_2024 UInt16 COMMENT '2024年专业录取人数'
+) ENGINE = MergeTree()
+ORDER BY (id, school_code, major_code)
+SETTINGS index_granularity = 8192;
+
+-- 历年录取数据长表
+CREATE TABLE IF NOT EXISTS admission_history (
+	year             UInt16 COMMENT '录取年份',
+	source_province  LowCardinality(String) COMMENT '生源省份',
+	subject_category LowCardinality(String) COMMENT '科类',
+	admission_batch  LowCardinality(String) COMMENT '录取批次',
+	school_code      String COMMENT '院校代码',
+	major_group_code String COMMENT '专业组代码',
+	major_code       String COMMENT '专业代码，为空表示专业组',
+	min_score        UInt16 COMMENT '最低分',
+	min_rank         UInt32 COMMENT '最低位次',
+	avg_score        UInt16 COMMENT '平均分',
+	max_score        UInt16 COMMENT '最高分',
+	enrollment_plan  UInt16 COMMENT '招生计划数',
+	admission_num    UInt16 COMMENT '录取人数'
+) ENGINE = ReplacingMergeTree()
+ORDER BY (source_province, subject_category, year, school_code, major_group_code, major_code)
+SETTINGS index_granularity = 8192;
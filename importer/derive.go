@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"reflect"
+
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/subjectreq"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+// 选考科目 -> 选科要求字段
+var requirementFields = map[string]string{
+	"物理": "require_physics",
+	"化学": "require_chemistry",
+	"生物": "require_biology",
+	"政治": "require_politics",
+	"历史": "require_history",
+	"地理": "require_geography",
+}
+
+// 解析原始选科限制，得到结构化选科要求 subject_requirement。
+// setFlags 为true（表格中没有 require_* 列）时同时推导 require_* 字段：
+// 无论哪种选法都要选的科目置为true，"化学或生物" 这类多选一的科目为false
+func deriveRequirements(row *models.AdmissionHubeiWide, setFlags bool) error {
+	requirement, err := subjectreq.Parse(row.SubjectRequirementRaw)
+	if err != nil {
+		return err
+	}
+	row.SubjectRequirement = requirement.DNF()
+	if !setFlags {
+		return nil
+	}
+
+	value := reflect.ValueOf(row).Elem()
+	for _, subject := range requirement.Required() {
+		value.Field(fieldIndex[requirementFields[subject]]).SetBool(true)
+	}
+	return nil
+}
+
+// 根据专业分类体系推导 is_* 专业分类标签：专业代码属于分类体系中的专业类时按学科门类和专业类，
+// 否则按专业名称关键词
+func deriveInterestFlags(tax *taxonomy.Taxonomy, row *models.AdmissionHubeiWide) {
+	for i := range tax.Interests {
+		interest := &tax.Interests[i]
+		if tax.MatchInterest(interest, row.MajorCode, row.MajorName) {
+			taxonomy.SetFlag(row, interest.Flag, true)
+		}
+	}
+}
+
+// 专业类别为空时按专业代码补全为专业类名称
+func deriveMajorCategory(tax *taxonomy.Taxonomy, row *models.AdmissionHubeiWide) {
+	if row.MajorCategory != "" {
+		return
+	}
+	if category, ok := tax.CategoryOf(row.MajorCode); ok {
+		row.MajorCategory = category.Name
+	}
+}
@@ -0,0 +1,324 @@
+// Package importer 将各省份官方录取数据表格（xlsx/csv）转换为gaokao2025录取数据
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+// Options 导入选项
+type Options struct {
+	// 表格中没有生源地列时使用的省份
+	Province string
+	// 表格中没有id列时，从该值开始分配记录ID
+	StartID uint32
+	// 专业分类体系，用于推导 is_* 标签和专业类别
+	Taxonomy *taxonomy.Taxonomy
+}
+
+// RowError 行级校验错误
+type RowError struct {
+	Line    int    // 表格中的行号（表头为第1行）
+	Column  string // 字段名，整行错误时为空
+	Value   string
+	Message string
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("第%d行: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("第%d行字段 %s=%q: %s", e.Line, e.Column, e.Value, e.Message)
+}
+
+// Result 解析结果，Rows 只包含校验通过的行
+type Result struct {
+	Rows           []models.AdmissionHubeiWide
+	Errors         []RowError
+	Total          int      // 数据行数（不含表头和空行）
+	UnknownColumns []string // 无法识别、已忽略的表头
+}
+
+// gaokao2025中Enum8字段的取值
+var enumValues = []struct {
+	Column  string
+	Allowed []string
+}{
+	{"school_ownership", []string{"公办", "内地与港澳台合作办学", "中外合作办学", "民办", "境外高校独立办学"}},
+	{"education_level", []string{"本科", "职业本科", "专科"}},
+}
+
+// 必填字段
+var requiredColumns = []string{"school_code", "school_name", "major_name", "major_group_code", "subject_category", "admission_batch"}
+
+// 中文学制
+var chineseNumbers = map[string]string{"二": "2", "两": "2", "三": "3", "四": "4", "五": "5", "六": "6", "七": "7", "八": "8"}
+
+// Parse 解析表格数据（第一行为表头），逐行校验并推导 require_*、is_* 与专业类别字段
+func Parse(records [][]string, opts Options) (*Result, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("表格为空")
+	}
+
+	columns, unknown := resolveColumns(records[0])
+	for _, required := range requiredColumns {
+		if !hasAnyColumn(columns, []string{required}) {
+			return nil, fmt.Errorf("缺少必需的列: %s", required)
+		}
+	}
+	if opts.Province == "" && !hasAnyColumn(columns, []string{"source_province"}) {
+		return nil, fmt.Errorf("表格中没有生源地列，需要指定省份")
+	}
+	if opts.Taxonomy == nil {
+		return nil, fmt.Errorf("缺少专业分类体系")
+	}
+
+	hasID := hasAnyColumn(columns, []string{"id"})
+	hasRequirements := hasAnyColumn(columns, requirementColumns)
+	hasInterestFlags := hasAnyColumn(columns, interestFlagColumns)
+
+	result := &Result{UnknownColumns: unknown}
+	seen := make(map[string]int)
+	nextID := opts.StartID
+
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlank(record) {
+			continue
+		}
+		result.Total++
+
+		row := models.AdmissionHubeiWide{SourceProvince: opts.Province}
+		value := reflect.ValueOf(&row).Elem()
+		var errs []RowError
+		for j, cell := range record {
+			if j >= len(columns) || columns[j] == "" {
+				continue
+			}
+			if err := setCell(value.Field(fieldIndex[columns[j]]), columns[j], cell); err != nil {
+				errs = append(errs, RowError{Line: line, Column: columns[j], Value: cell, Message: err.Error()})
+			}
+		}
+
+		// 表格已有 require_* 列时，无法识别的选科限制不作为错误，查询时按 require_* 字段匹配
+		if err := deriveRequirements(&row, !hasRequirements); err != nil && !hasRequirements {
+			errs = append(errs, RowError{Line: line, Column: "subject_requirement_raw", Value: row.SubjectRequirementRaw, Message: err.Error()})
+		}
+		if !hasInterestFlags {
+			deriveInterestFlags(opts.Taxonomy, &row)
+		}
+		deriveMajorCategory(opts.Taxonomy, &row)
+		if row.EducationLevel == "" {
+			row.EducationLevel = educationLevelForBatch(row.AdmissionBatch)
+		}
+
+		errs = append(errs, validateRow(&row, line)...)
+
+		key := strings.Join([]string{row.SourceProvince, row.SubjectCategory, row.AdmissionBatch, row.SchoolCode, row.MajorGroupCode, row.MajorCode, row.MajorName}, "|")
+		if first, ok := seen[key]; ok {
+			errs = append(errs, RowError{Line: line, Message: fmt.Sprintf("与第%d行重复", first)})
+		} else {
+			seen[key] = line
+		}
+
+		if len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			continue
+		}
+
+		if !hasID {
+			nextID++
+			row.ID = nextID
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
+
+// 校验必填字段、省份配置、枚举取值和分数范围
+func validateRow(row *models.AdmissionHubeiWide, line int) []RowError {
+	var errs []RowError
+	fail := func(column, value, message string) {
+		errs = append(errs, RowError{Line: line, Column: column, Value: value, Message: message})
+	}
+
+	value := reflect.ValueOf(row).Elem()
+	for _, column := range requiredColumns {
+		if value.Field(fieldIndex[column]).String() == "" {
+			fail(column, "", "不能为空")
+		}
+	}
+
+	profile, ok := config.GetProvinceProfile(row.SourceProvince)
+	if !ok || row.SourceProvince == "" {
+		fail("source_province", row.SourceProvince, "不支持的省份")
+	} else {
+		if row.SubjectCategory != "" && !profile.HasCategory(row.SubjectCategory) {
+			fail("subject_category", row.SubjectCategory, profile.Name+"不支持该科类")
+		}
+		if row.AdmissionBatch != "" && !profile.HasBatch(row.AdmissionBatch) {
+			fail("admission_batch", row.AdmissionBatch, profile.Name+"不支持该批次")
+		}
+	}
+
+	for _, enum := range enumValues {
+		v := value.Field(fieldIndex[enum.Column]).String()
+		if !containsString(enum.Allowed, v) {
+			fail(enum.Column, v, "取值应为 "+strings.Join(enum.Allowed, "/"))
+		}
+	}
+
+	if row.MinScore2024 > 750 {
+		fail("min_score_2024", strconv.Itoa(int(row.MinScore2024)), "分数超出范围")
+	}
+	if row.MajorMinScore2024 > 750 {
+		fail("major_min_score_2024", strconv.Itoa(int(row.MajorMinScore2024)), "分数超出范围")
+	}
+	if row.MinScore2024 > 0 && row.MinRank2024 == 0 {
+		fail("min_rank_2024", "", "有最低分时位次不能为空")
+	}
+	return errs
+}
+
+// 按字段类型解析单元格，兼容表格中常见的写法（是/否、4年、640.0、-）
+func setCell(field reflect.Value, column, cell string) error {
+	cell = strings.TrimSpace(cell)
+	if cell == "-" || cell == "—" {
+		cell = ""
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if column == "tuition_fee" {
+			fee, err := normalizeTuitionFee(cell)
+			if err != nil {
+				return err
+			}
+			cell = fee
+		}
+		field.SetString(cell)
+	case reflect.Bool:
+		switch cell {
+		case "", "否", "0", "false", "FALSE", "N", "n", "×":
+			field.SetBool(false)
+		case "是", "1", "true", "TRUE", "Y", "y", "√", "新增":
+			field.SetBool(true)
+		default:
+			return fmt.Errorf("无法识别的布尔值")
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if cell == "" {
+			return nil
+		}
+		if column == "study_duration" {
+			cell = strings.TrimSuffix(cell, "年")
+			if n, ok := chineseNumbers[cell]; ok {
+				cell = n
+			}
+		}
+		v, err := strconv.ParseFloat(cell, 64)
+		if err != nil || v < 0 || v != math.Trunc(v) {
+			return fmt.Errorf("应为非负整数")
+		}
+		if v > float64(uint64(1)<<field.Type().Bits()-1) {
+			return fmt.Errorf("数值超出范围")
+		}
+		field.SetUint(uint64(v))
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", field.Kind())
+	}
+	return nil
+}
+
+// 学费统一为整数字符串，"待定" 等非数字写法视为未知
+func normalizeTuitionFee(cell string) (string, error) {
+	cell = strings.TrimSuffix(strings.TrimSuffix(cell, "元/年"), "元")
+	if cell == "" || cell == "待定" || cell == "未知" {
+		return "", nil
+	}
+	v, err := strconv.ParseFloat(cell, 64)
+	if err != nil || v < 0 {
+		return "", fmt.Errorf("学费应为数字")
+	}
+	return strconv.FormatInt(int64(v), 10), nil
+}
+
+// 表格中没有本科/专科列时根据批次判断
+func educationLevelForBatch(batch string) string {
+	if strings.Contains(batch, "专科") {
+		return "专科"
+	}
+	return "本科"
+}
+
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Provinces 返回行中出现的生源地，按首次出现顺序
+func Provinces(rows []models.AdmissionHubeiWide) []string {
+	var provinces []string
+	for i := range rows {
+		if !containsString(provinces, rows[i].SourceProvince) {
+			provinces = append(provinces, rows[i].SourceProvince)
+		}
+	}
+	return provinces
+}
+
+// Conflicts 返回与已有数据重复的行：生源地、院校代码、专业组代码和专业代码都相同
+func Conflicts(rows, existing []models.AdmissionHubeiWide) []models.AdmissionHubeiWide {
+	keys := make(map[string]bool, len(existing))
+	for i := range existing {
+		keys[admissionKey(&existing[i])] = true
+	}
+	var conflicts []models.AdmissionHubeiWide
+	for i := range rows {
+		if keys[admissionKey(&rows[i])] {
+			conflicts = append(conflicts, rows[i])
+		}
+	}
+	return conflicts
+}
+
+func admissionKey(row *models.AdmissionHubeiWide) string {
+	return strings.Join([]string{row.SourceProvince, row.SchoolCode, row.MajorGroupCode, row.MajorCode}, "|")
+}
+
+// WriteReport 将行级错误写为CSV校验报告
+func WriteReport(w io.Writer, errs []RowError) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"行号", "字段", "值", "错误"}); err != nil {
+		return err
+	}
+	for _, e := range errs {
+		if err := writer.Write([]string{strconv.Itoa(e.Line), e.Column, e.Value, e.Message}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
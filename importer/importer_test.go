@@ -0,0 +1,332 @@
+package importer
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+func loadTaxonomy(t *testing.T) *taxonomy.Taxonomy {
+	t.Helper()
+	file, err := os.Open("../hubei_data/major_taxonomy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	tax, err := taxonomy.Parse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tax
+}
+
+// 官方表格常见的中文表头
+var testHeader = []string{"院校代码", "院校名称", "专业组代码", "专业代码", "专业名称", "科类", "批次", "选科限制", "公私性质", "学制", "学费", "备注"}
+
+func testRecord(school, group, major, name, requirement string) []string {
+	return []string{school, "测试大学", group, major, name, "物理", "本科批", requirement, "公办", "四年", "5000元/年", ""}
+}
+
+func TestResolveColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		columns []string
+		unknown []string
+	}{
+		{"中文表头", []string{"院校代码", "专业名称", "专业组最低分_2024"}, []string{"school_code", "major_name", "min_score_2024"}, nil},
+		{"英文字段名", []string{"school_code", "tuition_fee", "is_medical"}, []string{"school_code", "tuition_fee", "is_medical"}, nil},
+		{"BOM与空白", []string{"\ufeff院校代码", " 学制 ", "study_years"}, []string{"school_code", "study_duration", "study_duration"}, nil},
+		{"无法识别的列", []string{"院校代码", "备注", ""}, []string{"school_code", "", ""}, []string{"备注"}},
+		{"结构化选科要求不能直接导入", []string{"subject_requirement"}, []string{""}, []string{"subject_requirement"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, unknown := resolveColumns(tt.header)
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(unknown, tt.unknown) {
+				t.Errorf("unknown = %q, want %q", unknown, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestSetCell(t *testing.T) {
+	tests := []struct {
+		column  string
+		cell    string
+		want    interface{}
+		wantErr bool
+	}{
+		{"school_name", " 武汉大学 ", "武汉大学", false},
+		{"school_name", "-", "", false},
+		{"is_new_major", "是", true, false},
+		{"is_new_major", "新增", true, false},
+		{"is_new_major", "√", true, false},
+		{"is_new_major", "否", false, false},
+		{"is_new_major", "", false, false},
+		{"is_new_major", "未知", false, true},
+		{"study_duration", "4", uint8(4), false},
+		{"study_duration", "5年", uint8(5), false},
+		{"study_duration", "四年", uint8(4), false},
+		{"study_duration", "—", uint8(0), false},
+		{"min_score_2024", "640.0", uint16(640), false},
+		{"min_score_2024", "640.5", uint16(0), true},
+		{"min_score_2024", "-1", uint16(0), true},
+		{"min_score_2024", "70000", uint16(0), true},
+		{"min_rank_2024", "12345", uint32(12345), false},
+		{"tuition_fee", "5000元/年", "5000", false},
+		{"tuition_fee", "一万", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column+"="+tt.cell, func(t *testing.T) {
+			var row models.AdmissionHubeiWide
+			field := reflect.ValueOf(&row).Elem().Field(fieldIndex[tt.column])
+			err := setCell(field, tt.column, tt.cell)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setCell error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && field.Interface() != tt.want {
+				t.Errorf("value = %#v, want %#v", field.Interface(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTuitionFee(t *testing.T) {
+	tests := []struct {
+		cell    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"待定", "", false},
+		{"未知", "", false},
+		{"5000元/年", "5000", false},
+		{"5000元", "5000", false},
+		{"6800.0", "6800", false},
+		{"免费", "", true},
+		{"-100", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			got, err := normalizeTuitionFee(tt.cell)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeTuitionFee(%q) error = %v, wantErr %v", tt.cell, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeTuitionFee(%q) = %q, want %q", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveRequirements(t *testing.T) {
+	tests := []struct {
+		raw       string
+		setFlags  bool
+		dnf       [][]string
+		physics   bool
+		chemistry bool
+		biology   bool
+		wantErr   bool
+	}{
+		{"不限", true, nil, false, false, false, false},
+		{"物理+化学", true, [][]string{{"物理", "化学"}}, true, true, false, false},
+		{"首选物理，再选化学或生物", true, [][]string{{"物理", "化学"}, {"物理", "生物"}}, true, false, false, false},
+		// 表格已有 require_* 列时只解析结构化选科要求
+		{"物理+化学", false, [][]string{{"物理", "化学"}}, false, false, false, false},
+		{"英语", true, nil, false, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			row := models.AdmissionHubeiWide{SubjectRequirementRaw: tt.raw}
+			err := deriveRequirements(&row, tt.setFlags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deriveRequirements error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(row.SubjectRequirement, tt.dnf) {
+				t.Errorf("SubjectRequirement = %v, want %v", row.SubjectRequirement, tt.dnf)
+			}
+			if row.RequirePhysics != tt.physics || row.RequireChemistry != tt.chemistry || row.RequireBiology != tt.biology {
+				t.Errorf("require physics/chemistry/biology = %v/%v/%v, want %v/%v/%v",
+					row.RequirePhysics, row.RequireChemistry, row.RequireBiology, tt.physics, tt.chemistry, tt.biology)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tax := loadTaxonomy(t)
+	records := [][]string{
+		testHeader,
+		testRecord("10486", "01", "080901", "计算机科学与技术", "物理+化学"),
+		{"", "", "", "", "", "", "", "", "", "", "", ""},
+		testRecord("10486", "02", "050201", "英语", "不限"),
+	}
+	result, err := Parse(records, Options{Province: "湖北", Taxonomy: tax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Rows) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("Total=%d Rows=%d Errors=%v", result.Total, len(result.Rows), result.Errors)
+	}
+	if !reflect.DeepEqual(result.UnknownColumns, []string{"备注"}) {
+		t.Errorf("UnknownColumns = %q", result.UnknownColumns)
+	}
+
+	row := result.Rows[0]
+	if row.SourceProvince != "湖北" || row.StudyDuration != 4 || row.TuitionFee != "5000" || row.EducationLevel != "本科" {
+		t.Errorf("row = %+v", row)
+	}
+	if !row.RequirePhysics || !row.RequireChemistry {
+		t.Errorf("require_* 未按选科限制推导: %+v", row)
+	}
+	if !row.IsEngineering || row.MajorCategory != "计算机类" {
+		t.Errorf("is_engineering=%v major_category=%q", row.IsEngineering, row.MajorCategory)
+	}
+	if !result.Rows[1].IsLanguage {
+		t.Errorf("英语专业应标记为语言类")
+	}
+}
+
+func TestParseMissingColumns(t *testing.T) {
+	tax := loadTaxonomy(t)
+	tests := []struct {
+		name   string
+		header []string
+		opts   Options
+		msg    string
+	}{
+		{"缺少必需列", []string{"院校代码", "院校名称"}, Options{Province: "湖北", Taxonomy: tax}, "缺少必需的列"},
+		{"没有生源地", testHeader, Options{Taxonomy: tax}, "需要指定省份"},
+		{"没有分类体系", testHeader, Options{Province: "湖北"}, "缺少专业分类体系"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([][]string{tt.header}, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("error = %v, want %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseRowErrors(t *testing.T) {
+	tax := loadTaxonomy(t)
+	badBatch := testRecord("10486", "03", "070101", "数学与应用数学", "物理")
+	badBatch[6] = "提前批"
+	records := [][]string{
+		testHeader,
+		testRecord("10486", "01", "080901", "计算机科学与技术", "物理+化学"),
+		testRecord("10486", "01", "080901", "计算机科学与技术", "物理+化学"),
+		testRecord("10486", "02", "080902", "软件工程", "英语"),
+		badBatch,
+	}
+	result, err := Parse(records, Options{Province: "湖北", Taxonomy: tax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 {
+		t.Errorf("Rows = %d, want 1", len(result.Rows))
+	}
+
+	var got []string
+	for _, e := range result.Errors {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		"第3行: 与第2行重复",
+		`第4行字段 subject_requirement_raw="英语": `,
+		`第5行字段 admission_batch="提前批": 湖北不支持该批次`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Errors = %q", got)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("Errors[%d] = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseAssignsIDs(t *testing.T) {
+	tax := loadTaxonomy(t)
+	records := [][]string{
+		testHeader,
+		testRecord("10486", "01", "080901", "计算机科学与技术", "物理+化学"),
+		testRecord("10486", "01", "080902", "软件工程", "物理+化学"),
+	}
+	tests := []struct {
+		name     string
+		existing []models.AdmissionHubeiWide
+		want     []uint32
+	}{
+		{"空表从1开始", nil, []uint32{1, 2}},
+		{"从最大ID之后分配", []models.AdmissionHubeiWide{{ID: 7}, {ID: 42}, {ID: 9}}, []uint32{43, 44}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewMemoryDBFromRows(tt.existing, nil, nil)
+			startID, err := db.MaxAdmissionID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Parse(records, Options{Province: "湖北", StartID: startID, Taxonomy: tax})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint32
+			for _, row := range result.Rows {
+				ids = append(ids, row.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	// 表格有id列时保留原ID
+	withID := [][]string{append([]string{"id"}, testHeader...), append([]string{"100"}, records[1]...)}
+	result, err := Parse(withID, Options{Province: "湖北", StartID: 42, Taxonomy: tax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].ID != 100 {
+		t.Errorf("Rows = %+v", result.Rows)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	existing := []models.AdmissionHubeiWide{
+		{SourceProvince: "湖北", SchoolCode: "10486", MajorGroupCode: "01", MajorCode: "080901"},
+		{SourceProvince: "湖南", SchoolCode: "10486", MajorGroupCode: "01", MajorCode: "080902"},
+	}
+	rows := []models.AdmissionHubeiWide{
+		{SourceProvince: "湖北", SchoolCode: "10486", MajorGroupCode: "01", MajorCode: "080901"},
+		{SourceProvince: "湖北", SchoolCode: "10486", MajorGroupCode: "01", MajorCode: "080902"},
+		{SourceProvince: "湖北", SchoolCode: "10486", MajorGroupCode: "02", MajorCode: "080901"},
+	}
+	provinces := Provinces(rows)
+	if !reflect.DeepEqual(provinces, []string{"湖北"}) {
+		t.Fatalf("Provinces = %q", provinces)
+	}
+
+	// 只按生源地查询已有数据，湖南的同代码专业不算重复
+	db := database.NewMemoryDBFromRows(existing, nil, nil)
+	keys, err := db.ListAdmissionKeys(provinces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicts := Conflicts(rows, keys)
+	if len(conflicts) != 1 || conflicts[0].MajorCode != "080901" || conflicts[0].MajorGroupCode != "01" {
+		t.Errorf("conflicts = %+v", conflicts)
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 表头别名 -> gaokao2025字段名，中文表头取自 hubei_data/field_mapping.md。
+// 表头也可以直接使用英文字段名（即模型的ch标签）
+var headerAliases = map[string]string{
+	"院校代码":         "school_code",
+	"院校名称":         "school_name",
+	"专业代码":         "major_code",
+	"专业名称":         "major_name",
+	"专业组代码":        "major_group_code",
+	"生源地":          "source_province",
+	"所在省":          "school_province",
+	"城市":           "school_city",
+	"批次":           "admission_batch",
+	"科类":           "subject_category",
+	"选科限制":         "subject_requirement_raw",
+	"类型":           "school_type",
+	"公私性质":         "school_ownership",
+	"隶属单位":         "school_authority",
+	"院校水平":         "school_level",
+	"院校标签":         "school_tags",
+	"本科/专科":        "education_level",
+	"专业备注":         "major_description",
+	"学制":           "study_duration",
+	"study_years":  "study_duration",
+	"学费":           "tuition_fee",
+	"新增专业":         "is_new_major",
+	"专业组最低分_2024":  "min_score_2024",
+	"专业组最低位次_2024": "min_rank_2024",
+	"专业最低分_2024":   "major_min_score_2024",
+	"计划数_2024":     "enrollment_plan_2024",
+	"理科":           "is_science",
+	"工科":           "is_engineering",
+	"医科":           "is_medical",
+	"经管法":          "is_economics_mgmt_law",
+	"文科（非经管法）":     "is_liberal_arts",
+	"设计与艺术类":       "is_design_arts",
+	"语言类":          "is_language",
+}
+
+// 专业分类标签字段，表格中出现任意一列时不再根据专业名称推导
+var interestFlagColumns = []string{
+	"is_science", "is_engineering", "is_medical", "is_economics_mgmt_law",
+	"is_liberal_arts", "is_design_arts", "is_language",
+}
+
+// 选科要求字段，表格中出现任意一列时不再根据原始选科限制推导
+var requirementColumns = []string{
+	"require_physics", "require_chemistry", "require_biology",
+	"require_politics", "require_history", "require_geography",
+}
+
+// 字段名 -> AdmissionHubeiWide 结构体字段下标
+var fieldIndex = func() map[string]int {
+	index := make(map[string]int)
+	modelType := reflect.TypeOf(models.AdmissionHubeiWide{})
+	for i := 0; i < modelType.NumField(); i++ {
+		// 结构化选科要求只能由原始选科限制解析得到
+		if tag := modelType.Field(i).Tag.Get("ch"); tag != "" && tag != "subject_requirement" {
+			index[tag] = i
+		}
+	}
+	return index
+}()
+
+// 解析表头，返回每一列对应的字段名（无法识别的列为空）和无法识别的表头
+func resolveColumns(header []string) (columns []string, unknown []string) {
+	columns = make([]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if field, ok := headerAliases[name]; ok {
+			columns[i] = field
+		} else if _, ok := fieldIndex[name]; ok {
+			columns[i] = name
+		} else if name != "" {
+			unknown = append(unknown, name)
+		}
+	}
+	return columns, unknown
+}
+
+func hasAnyColumn(columns []string, fields []string) bool {
+	for _, c := range columns {
+		for _, f := range fields {
+			if c == f {
+				return true
+			}
+		}
+	}
+	return false
+}
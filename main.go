@@ -1,17 +1,43 @@
 package main
 
 import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/controlline"
 	"gaokao-zhiyuan/database"
 	"gaokao-zhiyuan/handlers"
+	"gaokao-zhiyuan/recommend"
+	"gaokao-zhiyuan/scorerank"
+	"gaokao-zhiyuan/taxonomy"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// 编译时嵌入的一分一段表，未配置 SCORE_RANK_DIR 时使用
+//
+//go:embed hubei_data/ranking_score_*.json
+var embeddedScoreRanks embed.FS
+
+// 编译时嵌入的控制线数据，未配置 CONTROL_LINE_PATH 时使用
+//
+//go:embed hubei_data/control_lines.json
+var embeddedControlLines []byte
+
+// 编译时嵌入的专业分类体系，未配置 TAXONOMY_PATH 时使用
+//
+//go:embed hubei_data/major_taxonomy.json
+var embeddedTaxonomy []byte
+
 func main() {
 	// 加载.env文件
 	if err := godotenv.Load(); err != nil {
@@ -21,27 +47,59 @@ func main() {
 	// 加载配置
 	cfg := config.LoadConfig()
 
-	// 输出连接信息
-	log.Printf("使用ClickHouse连接: %s:%d, 用户: %s, 数据库: %s",
-		cfg.ClickHouseHost, cfg.ClickHousePort, cfg.ClickHouseUser, cfg.ClickHouseDatabase)
+	// 表结构迁移子命令
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
 
 	// 设置Gin模式
 	gin.SetMode(cfg.GinMode)
 
-	// 连接数据库
-	db, err := database.NewClickHouseDB(cfg)
+	// 加载一分一段表
+	ranks, err := loadScoreRanks(cfg)
 	if err != nil {
-		log.Fatalf("连接ClickHouse失败: %v", err)
+		log.Fatalf("加载一分一段表失败: %v", err)
+	}
+
+	// 加载控制线
+	lines, err := loadControlLines(cfg)
+	if err != nil {
+		log.Fatalf("加载控制线失败: %v", err)
+	}
+
+	// 加载专业分类体系
+	tax, err := loadTaxonomy(cfg)
+	if err != nil {
+		log.Fatalf("加载专业分类体系失败: %v", err)
+	}
+	log.Printf("专业分类体系版本: %s", tax.Version)
+
+	// 打开存储
+	db, err := openStore(cfg, ranks)
+	if err != nil {
+		log.Fatalf("打开存储失败: %v", err)
 	}
 	defer db.Close()
 
-	// 创建表（如果不存在）
-	if err := db.CreateTable(); err != nil {
-		log.Fatalf("创建表失败: %v", err)
+	// 录取概率模型及冲稳保划分窗口
+	opts := recommend.Options{SigmaPoints: cfg.ProbabilitySigmaPoints}
+	if opts.Bands, err = recommend.ParseBands(cfg.ProbabilityBands); err != nil {
+		log.Fatalf("PROBABILITY_BANDS 配置错误: %v", err)
+	}
+	if opts.RankWindows, err = recommend.ParseRankWindows(cfg.RankWindows); err != nil {
+		log.Fatalf("RANK_WINDOWS 配置错误: %v", err)
+	}
+	if opts.LineDiffWindows, err = recommend.ParseLineDiffWindows(cfg.LineDiffWindows); err != nil {
+		log.Fatalf("LINE_DIFF_WINDOWS 配置错误: %v", err)
 	}
+	if opts.TierRatio, err = recommend.ParseTierRatio(cfg.PlanTierRatio); err != nil {
+		log.Fatalf("PLAN_TIER_RATIO 配置错误: %v", err)
+	}
+	model := recommend.NewModel(ranks, lines, opts)
 
 	// 创建处理器
-	handler := handlers.NewHandler(db)
+	handler := handlers.NewHandler(db, ranks, lines, model, tax)
 
 	// 创建路由
 	router := setupRouter(handler)
@@ -53,6 +111,102 @@ func main() {
 	}
 }
 
+// 加载一分一段表并检查必需的表是否齐全
+func loadScoreRanks(cfg *config.Config) (*scorerank.Registry, error) {
+	var fsys fs.FS
+	if cfg.ScoreRankDir != "" {
+		log.Printf("从目录 %s 加载一分一段表", cfg.ScoreRankDir)
+		fsys = os.DirFS(cfg.ScoreRankDir)
+	} else {
+		sub, err := fs.Sub(embeddedScoreRanks, "hubei_data")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+
+	ranks, err := scorerank.Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range cfg.ScoreRankRequired {
+		province, yearStr, found := strings.Cut(item, ":")
+		year, err := strconv.Atoi(yearStr)
+		if !found || err != nil {
+			return nil, fmt.Errorf("SCORE_RANK_REQUIRED 格式错误: %s（应为 省份:年份）", item)
+		}
+		if err := ranks.Require(province, year); err != nil {
+			return nil, err
+		}
+	}
+	return ranks, nil
+}
+
+// 加载控制线数据
+func loadControlLines(cfg *config.Config) (*controlline.Set, error) {
+	if cfg.ControlLinePath == "" {
+		return controlline.Parse(bytes.NewReader(embeddedControlLines))
+	}
+
+	log.Printf("从文件 %s 加载控制线", cfg.ControlLinePath)
+	file, err := os.Open(cfg.ControlLinePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return controlline.Parse(file)
+}
+
+// 加载专业分类体系
+func loadTaxonomy(cfg *config.Config) (*taxonomy.Taxonomy, error) {
+	if cfg.TaxonomyPath == "" {
+		return taxonomy.Parse(bytes.NewReader(embeddedTaxonomy))
+	}
+
+	log.Printf("从文件 %s 加载专业分类体系", cfg.TaxonomyPath)
+	file, err := os.Open(cfg.TaxonomyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return taxonomy.Parse(file)
+}
+
+// 根据配置打开录取数据存储
+func openStore(cfg *config.Config, ranks *scorerank.Registry) (database.AdmissionStore, error) {
+	switch cfg.StoreBackend {
+	case "memory":
+		log.Printf("使用内存存储，快照文件: %s", cfg.SnapshotPath)
+		return database.NewMemoryDB(cfg.SnapshotPath, cfg.HistorySnapshotPath, ranks)
+	case "clickhouse":
+		// 输出连接信息
+		log.Printf("使用ClickHouse连接: %s:%d, 用户: %s, 数据库: %s",
+			cfg.ClickHouseHost, cfg.ClickHousePort, cfg.ClickHouseUser, cfg.ClickHouseDatabase)
+
+		// 连接数据库
+		db, err := database.NewClickHouseDB(cfg, ranks)
+		if err != nil {
+			return nil, fmt.Errorf("连接ClickHouse失败: %v", err)
+		}
+
+		// 执行表结构迁移并校验
+		if cfg.MigrateOnStart {
+			if _, err := db.Migrate(); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("执行表结构迁移失败: %v", err)
+			}
+		}
+		if err := db.VerifySchema(); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("未知的存储后端: %s", cfg.StoreBackend)
+	}
+}
+
 func setupRouter(handler *handlers.Handler) *gin.Engine {
 	router := gin.Default()
 
@@ -88,6 +242,42 @@ func setupRouter(handler *handlers.Handler) *gin.Engine {
 	{
 		// 高级查询位次接口
 		v1.POST("/query_rank", handler.QueryRank)
+
+		// 位次换算分数接口
+		v1.GET("/score_by_rank", handler.GetScoreByRank)
+
+		// 等位分换算接口
+		v1.GET("/equivalent_score", handler.GetEquivalentScore)
+
+		// 控制线查询接口
+		v1.GET("/control_lines", handler.GetControlLines)
+
+		// 省份配置接口
+		v1.GET("/provinces", handler.GetProvinces)
+
+		// 报表查询接口 - 结构化筛选条件
+		v1.POST("/report", handler.PostReport)
+		v1.POST("/report/export", handler.ExportReport)
+
+		// 志愿表生成接口
+		v1.POST("/plan/generate", handler.GeneratePlan)
+
+		// 服从调剂风险分析接口
+		v1.POST("/reassignment/analyze", handler.AnalyzeReassignment)
+
+		// 专业分类体系接口
+		v1.GET("/taxonomy", handler.GetTaxonomy)
+
+		// 选科组合对比接口
+		v1.POST("/subjects/compare", handler.CompareSubjectCombinations)
+
+		// 院校详情接口
+		v1.GET("/schools/:school_code", handler.GetSchool)
+
+		// 专业搜索、专业详情接口
+		v1.GET("/majors", handler.SearchMajors)
+		v1.GET("/majors/:major_code", handler.GetMajor)
+		v1.POST("/compare", handler.CompareGroups)
 	}
 
 	return router
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/database"
+)
+
+const migrateUsage = `用法: gaokao-server migrate <命令>
+
+命令:
+  up       执行所有未执行的迁移并校验表结构
+  status   列出迁移及执行状态
+  schema   输出由模型生成的建表语句（-format sql）或字段文档（-format markdown）
+`
+
+// 表结构迁移子命令
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "schema":
+		flags := flag.NewFlagSet("migrate schema", flag.ExitOnError)
+		format := flags.String("format", "sql", "输出格式: sql 或 markdown")
+		flags.Parse(args[1:])
+		if err := printSchema(*format); err != nil {
+			log.Fatalf("%v", err)
+		}
+	case "up", "status":
+		db, err := database.NewClickHouseDB(cfg, nil)
+		if err != nil {
+			log.Fatalf("连接ClickHouse失败: %v", err)
+		}
+		defer db.Close()
+
+		if args[0] == "up" {
+			applied, err := db.Migrate()
+			if err != nil {
+				log.Fatalf("执行表结构迁移失败: %v", err)
+			}
+			if err := db.VerifySchema(); err != nil {
+				log.Fatalf("%v", err)
+			}
+			log.Printf("本次执行 %d 个迁移，表结构与模型一致", len(applied))
+			return
+		}
+
+		states, err := db.MigrationStatus()
+		if err != nil {
+			log.Fatalf("查询迁移状态失败: %v", err)
+		}
+		for _, state := range states {
+			status := "未执行"
+			if state.Applied {
+				status = "已执行 " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", state.Version, state.Name, status)
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+// 输出由模型标签生成的表结构
+func printSchema(format string) error {
+	switch format {
+	case "sql":
+		fmt.Println("-- 由 models 结构体标签生成（make schema），请勿手动修改。表结构变更见 database/migrations")
+	case "markdown":
+		fmt.Println("<!-- 由 models 结构体标签生成（make schema），请勿手动修改。表结构变更见 database/migrations -->")
+	default:
+		return fmt.Errorf("未知的输出格式: %s", format)
+	}
+
+	for _, table := range database.SchemaTables() {
+		fmt.Println()
+		if format == "sql" {
+			fmt.Print(table.CreateTableSQL())
+		} else {
+			fmt.Print(table.Markdown())
+		}
+	}
+	return nil
+}
@@ -1,59 +1,82 @@
 package models
 
+// gaokao2025宽表中 *_2024 字段对应的录取年份，也是报表的默认参考年份
+const ReferenceAdmissionYear = 2024
+
 // 录取数据表结构 - 新的ClickHouse表结构 (gaokao2025)
+// ch/chtype/comment 标签是表结构的唯一来源：迁移后的表结构按标签校验，
+// DDL和字段文档由 `gaokao-server migrate schema` 从标签生成
 type AdmissionHubeiWide struct {
-	ID                    uint32 `json:"id" ch:"id"`
-	SchoolCode            string `json:"school_code" ch:"school_code"`
-	SchoolName            string `json:"school_name" ch:"school_name"`
-	MajorCode             string `json:"major_code" ch:"major_code"`
-	MajorName             string `json:"major_name" ch:"major_name"`
-	MajorGroupCode        string `json:"major_group_code" ch:"major_group_code"`
-	SourceProvince        string `json:"source_province" ch:"source_province"`
-	SchoolProvince        string `json:"school_province" ch:"school_province"`
-	SchoolCity            string `json:"school_city" ch:"school_city"`
-	AdmissionBatch        string `json:"admission_batch" ch:"admission_batch"`
-	SubjectCategory       string `json:"subject_category" ch:"subject_category"`
-	RequirePhysics        bool   `json:"require_physics" ch:"require_physics"`
-	RequireChemistry      bool   `json:"require_chemistry" ch:"require_chemistry"`
-	RequireBiology        bool   `json:"require_biology" ch:"require_biology"`
-	RequirePolitics       bool   `json:"require_politics" ch:"require_politics"`
-	RequireHistory        bool   `json:"require_history" ch:"require_history"`
-	RequireGeography      bool   `json:"require_geography" ch:"require_geography"`
-	SubjectRequirementRaw string `json:"subject_requirement_raw" ch:"subject_requirement_raw"`
-	SchoolType            string `json:"school_type" ch:"school_type"`
-	SchoolOwnership       string `json:"school_ownership" ch:"school_ownership"`
-	SchoolAuthority       string `json:"school_authority" ch:"school_authority"`
-	SchoolLevel           string `json:"school_level" ch:"school_level"`
-	SchoolTags            string `json:"school_tags" ch:"school_tags"`
-	EducationLevel        string `json:"education_level" ch:"education_level"`
-	MajorDescription      string `json:"major_description" ch:"major_description"`
-	StudyDuration         uint8  `json:"study_duration" ch:"study_duration"`
-	TuitionFee            string `json:"tuition_fee" ch:"tuition_fee"`
-	IsNewMajor            bool   `json:"is_new_major" ch:"is_new_major"`
-	MinScore2024          uint16 `json:"min_score_2024" ch:"min_score_2024"`
-	MinRank2024           uint32 `json:"min_rank_2024" ch:"min_rank_2024"`
-	MajorMinScore2024     uint16 `json:"major_min_score_2024" ch:"major_min_score_2024"`
-	EnrollmentPlan2024    uint16 `json:"enrollment_plan_2024" ch:"enrollment_plan_2024"`
-	IsScience             bool   `json:"is_science" ch:"is_science"`
-	IsEngineering         bool   `json:"is_engineering" ch:"is_engineering"`
-	IsMedical             bool   `json:"is_medical" ch:"is_medical"`
-	IsEconomicsMgmtLaw    bool   `json:"is_economics_mgmt_law" ch:"is_economics_mgmt_law"`
-	IsLiberalArts         bool   `json:"is_liberal_arts" ch:"is_liberal_arts"`
-	IsDesignArts          bool   `json:"is_design_arts" ch:"is_design_arts"`
-	IsLanguage            bool   `json:"is_language" ch:"is_language"`
+	ID                    uint32     `json:"id" ch:"id" chtype:"UInt32" comment:"记录唯一标识"`
+	SchoolCode            string     `json:"school_code" ch:"school_code" chtype:"String" comment:"院校代码"`
+	SchoolName            string     `json:"school_name" ch:"school_name" chtype:"String" comment:"院校名称"`
+	MajorCode             string     `json:"major_code" ch:"major_code" chtype:"String" comment:"专业代码"`
+	MajorName             string     `json:"major_name" ch:"major_name" chtype:"String" comment:"专业名称"`
+	MajorGroupCode        string     `json:"major_group_code" ch:"major_group_code" chtype:"String" comment:"专业组代码"`
+	SourceProvince        string     `json:"source_province" ch:"source_province" chtype:"LowCardinality(String)" comment:"生源省份"`
+	SchoolProvince        string     `json:"school_province" ch:"school_province" chtype:"String" comment:"院校所在省份"`
+	SchoolCity            string     `json:"school_city" ch:"school_city" chtype:"String" comment:"院校所在城市"`
+	AdmissionBatch        string     `json:"admission_batch" ch:"admission_batch" chtype:"LowCardinality(String)" comment:"录取批次"`
+	SubjectCategory       string     `json:"subject_category" ch:"subject_category" chtype:"LowCardinality(String)" comment:"科类：物理、历史或综合"`
+	RequirePhysics        bool       `json:"require_physics" ch:"require_physics" chtype:"Bool" comment:"是否要求选择物理"`
+	RequireChemistry      bool       `json:"require_chemistry" ch:"require_chemistry" chtype:"Bool" comment:"是否要求选择化学"`
+	RequireBiology        bool       `json:"require_biology" ch:"require_biology" chtype:"Bool" comment:"是否要求选择生物"`
+	RequirePolitics       bool       `json:"require_politics" ch:"require_politics" chtype:"Bool" comment:"是否要求选择政治"`
+	RequireHistory        bool       `json:"require_history" ch:"require_history" chtype:"Bool" comment:"是否要求选择历史"`
+	RequireGeography      bool       `json:"require_geography" ch:"require_geography" chtype:"Bool" comment:"是否要求选择地理"`
+	SubjectRequirementRaw string     `json:"subject_requirement_raw" ch:"subject_requirement_raw" chtype:"String" comment:"原始选科限制描述"`
+	SubjectRequirement    [][]string `json:"subject_requirement" ch:"subject_requirement" chtype:"Array(Array(String))" comment:"选科要求（析取范式，选齐任一组内的科目即满足，空数组为不限），导入时由原始选科限制解析"`
+	SchoolType            string     `json:"school_type" ch:"school_type" chtype:"String" comment:"院校类型"`
+	SchoolOwnership       string     `json:"school_ownership" ch:"school_ownership" chtype:"Enum8('公办' = 1, '内地与港澳台合作办学' = 2, '中外合作办学' = 3, '民办' = 4, '境外高校独立办学' = 5)" comment:"公私性质"`
+	SchoolAuthority       string     `json:"school_authority" ch:"school_authority" chtype:"String" comment:"院校隶属单位"`
+	SchoolLevel           string     `json:"school_level" ch:"school_level" chtype:"String" comment:"院校水平层次"`
+	SchoolTags            string     `json:"school_tags" ch:"school_tags" chtype:"String" comment:"院校标签，如985、211等"`
+	EducationLevel        string     `json:"education_level" ch:"education_level" chtype:"Enum8('本科' = 1, '职业本科' = 2, '专科' = 3)" comment:"本科/专科"`
+	MajorDescription      string     `json:"major_description" ch:"major_description" chtype:"String" comment:"专业备注信息"`
+	StudyDuration         uint8      `json:"study_duration" ch:"study_duration" chtype:"UInt8" comment:"学制年数"`
+	TuitionFee            string     `json:"tuition_fee" ch:"tuition_fee" chtype:"String" comment:"学费（元/年）"`
+	IsNewMajor            bool       `json:"is_new_major" ch:"is_new_major" chtype:"Bool" comment:"是否为新增专业"`
+	MinScore2024          uint16     `json:"min_score_2024" ch:"min_score_2024" chtype:"UInt16" comment:"2024年专业组最低录取分数"`
+	MinRank2024           uint32     `json:"min_rank_2024" ch:"min_rank_2024" chtype:"UInt32" comment:"2024年专业组最低录取位次"`
+	MajorMinScore2024     uint16     `json:"major_min_score_2024" ch:"major_min_score_2024" chtype:"UInt16" comment:"2024年专业最低录取分数"`
+	EnrollmentPlan2024    uint16     `json:"enrollment_plan_2024" ch:"enrollment_plan_2024" chtype:"UInt16" comment:"2024年招生计划数"`
+	IsScience             bool       `json:"is_science" ch:"is_science" chtype:"Bool" comment:"是否为理科专业"`
+	IsEngineering         bool       `json:"is_engineering" ch:"is_engineering" chtype:"Bool" comment:"是否为工科专业"`
+	IsMedical             bool       `json:"is_medical" ch:"is_medical" chtype:"Bool" comment:"是否为医科专业"`
+	IsEconomicsMgmtLaw    bool       `json:"is_economics_mgmt_law" ch:"is_economics_mgmt_law" chtype:"Bool" comment:"是否为经管法专业"`
+	IsLiberalArts         bool       `json:"is_liberal_arts" ch:"is_liberal_arts" chtype:"Bool" comment:"是否为文科专业（非经管法）"`
+	IsDesignArts          bool       `json:"is_design_arts" ch:"is_design_arts" chtype:"Bool" comment:"是否为设计与艺术类专业"`
+	IsLanguage            bool       `json:"is_language" ch:"is_language" chtype:"Bool" comment:"是否为语言类专业"`
 	// 新增字段
-	EnrollmentPlan        uint16 `json:"enrollment_plan,omitempty" ch:"enrollment_plan"`
-	MajorID               string `json:"major_id,omitempty" ch:"major_id"`
-	EnrollmentType        string `json:"enrollment_type,omitempty" ch:"enrollment_type"`
-	EnrollmentPlanYear    uint16 `json:"enrollment_plan_year,omitempty" ch:"enrollment_plan_year"`
-	MajorCategory         string `json:"major_category,omitempty" ch:"major_category"`
-	AdmissionNum2024      uint16 `json:"admission_num_2024,omitempty" ch:"admission_num_2024"`
-	MajorMinRank2024      uint32 `json:"major_min_rank_2024,omitempty" ch:"major_min_rank_2024"`
-	MajorAvgScore2024     uint16 `json:"major_avg_score_2024,omitempty" ch:"major_avg_score_2024"`
-	MajorAvgRank2024      uint32 `json:"major_avg_rank_2024,omitempty" ch:"major_avg_rank_2024"`
-	MajorMaxScore2024     uint16 `json:"major_max_score_2024,omitempty" ch:"major_max_score_2024"`
-	MajorMaxRank2024      uint32 `json:"major_max_rank_2024,omitempty" ch:"major_max_rank_2024"`
-	MajorAdmissionNum2024 uint16 `json:"major_admission_num_2024,omitempty" ch:"major_admission_num_2024"`
+	EnrollmentPlan        uint16 `json:"enrollment_plan,omitempty" ch:"enrollment_plan" chtype:"UInt16" comment:"当年招生计划数"`
+	MajorID               string `json:"major_id,omitempty" ch:"major_id" chtype:"String" comment:"专业ID"`
+	EnrollmentType        string `json:"enrollment_type,omitempty" ch:"enrollment_type" chtype:"LowCardinality(String)" comment:"招生类型"`
+	EnrollmentPlanYear    uint16 `json:"enrollment_plan_year,omitempty" ch:"enrollment_plan_year" chtype:"UInt16" comment:"招生计划年份"`
+	MajorCategory         string `json:"major_category,omitempty" ch:"major_category" chtype:"LowCardinality(String)" comment:"专业类别"`
+	AdmissionNum2024      uint16 `json:"admission_num_2024,omitempty" ch:"admission_num_2024" chtype:"UInt16" comment:"2024年专业组录取人数"`
+	MajorMinRank2024      uint32 `json:"major_min_rank_2024,omitempty" ch:"major_min_rank_2024" chtype:"UInt32" comment:"2024年专业最低录取位次"`
+	MajorAvgScore2024     uint16 `json:"major_avg_score_2024,omitempty" ch:"major_avg_score_2024" chtype:"UInt16" comment:"2024年专业平均分"`
+	MajorAvgRank2024      uint32 `json:"major_avg_rank_2024,omitempty" ch:"major_avg_rank_2024" chtype:"UInt32" comment:"2024年专业平均位次"`
+	MajorMaxScore2024     uint16 `json:"major_max_score_2024,omitempty" ch:"major_max_score_2024" chtype:"UInt16" comment:"2024年专业最高分"`
+	MajorMaxRank2024      uint32 `json:"major_max_rank_2024,omitempty" ch:"major_max_rank_2024" chtype:"UInt32" comment:"2024年专业最高位次"`
+	MajorAdmissionNum2024 uint16 `json:"major_admission_num_2024,omitempty" ch:"major_admission_num_2024" chtype:"UInt16" comment:"2024年专业录取人数"`
+}
+
+// 历年录取数据（admission_history长表），major_code为空表示专业组层级，标签用法同 AdmissionHubeiWide
+type AdmissionHistory struct {
+	Year            uint16 `json:"year" ch:"year" chtype:"UInt16" comment:"录取年份"`
+	SourceProvince  string `json:"source_province" ch:"source_province" chtype:"LowCardinality(String)" comment:"生源省份"`
+	SubjectCategory string `json:"subject_category" ch:"subject_category" chtype:"LowCardinality(String)" comment:"科类"`
+	AdmissionBatch  string `json:"admission_batch" ch:"admission_batch" chtype:"LowCardinality(String)" comment:"录取批次"`
+	SchoolCode      string `json:"school_code" ch:"school_code" chtype:"String" comment:"院校代码"`
+	MajorGroupCode  string `json:"major_group_code" ch:"major_group_code" chtype:"String" comment:"专业组代码"`
+	MajorCode       string `json:"major_code" ch:"major_code" chtype:"String" comment:"专业代码，为空表示专业组"`
+	MinScore        uint16 `json:"min_score" ch:"min_score" chtype:"UInt16" comment:"最低分"`
+	MinRank         uint32 `json:"min_rank" ch:"min_rank" chtype:"UInt32" comment:"最低位次"`
+	AvgScore        uint16 `json:"avg_score" ch:"avg_score" chtype:"UInt16" comment:"平均分"`
+	MaxScore        uint16 `json:"max_score" ch:"max_score" chtype:"UInt16" comment:"最高分"`
+	EnrollmentPlan  uint16 `json:"enrollment_plan" ch:"enrollment_plan" chtype:"UInt16" comment:"招生计划数"`
+	AdmissionNum    uint16 `json:"admission_num" ch:"admission_num" chtype:"UInt16" comment:"录取人数"`
 }
 
 // 旧的录取数据表结构（保持兼容性）
@@ -75,6 +98,58 @@ type AdmissionData struct {
 	StudyYears               string `json:"study_years,omitempty" ch:"study_duration"`                    // 学制
 }
 
+// 报表查询参数，省份、科类和批次由调用方按省份配置补全默认值
+type ReportQuery struct {
+	Rank                 int64    // 位次
+	Year                 int      // 参考录取年份，筛选时比较该年的录取分数线
+	ExamYear             int      // 考生参加高考的年份，录取分数线换算为该年的等位分
+	HistoryYears         int      // 返回最近几年的历年录取数据
+	Province             string   // 生源省份
+	ClassFirstChoice     string   // 首选科目（科类）
+	ClassOptionalChoice  []string // 再选科目
+	Batch                string   // 录取批次
+	Page                 int64
+	PageSize             int64
+	CollegeLocation      []string // 院校所在省份
+	InterestFlags        []string // 意向专业方向对应的 is_* 标签字段，满足任一即可
+	MajorCategories      []string // 专业类代码（专业代码前4位），满足任一即可
+	MinCutoffRank        int64    // 参考年份专业组录取位次下限，由冲稳保策略换算
+	MaxCutoffRank        int64    // 参考年份专业组录取位次上限，为0时不按位次筛选
+	MinCutoffScore       int64    // 参考年份专业组录取分下限，由线差法换算
+	MaxCutoffScore       int64    // 参考年份专业组录取分上限，为0时不按分数筛选
+	FuzzySubjectCategory string   // 专业名称模糊查询
+	MajorCode            string   // 专业代码，精确匹配
+	GroupByMajorGroup    bool     // 按院校专业组聚合，分页和总数都以专业组计
+	Filters              ReportFilters
+}
+
+// 字符串字段的包含/排除条件，include 为空时不限，exclude 中的取值一律排除
+type StringFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// 数值字段的闭区间条件，min/max 为空时该侧不限
+type RangeFilter struct {
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+}
+
+// 报表的结构化筛选条件
+type ReportFilters struct {
+	SchoolTags      StringFilter `json:"school_tags"`      // 院校标签，按标签包含匹配，如 985、211、双一流
+	SchoolLevel     StringFilter `json:"school_level"`     // 院校水平层次
+	SchoolOwnership StringFilter `json:"school_ownership"` // 公私性质
+	SchoolType      StringFilter `json:"school_type"`      // 院校类型
+	SchoolProvince  StringFilter `json:"school_province"`  // 院校所在省份
+	SchoolCity      StringFilter `json:"school_city"`      // 院校所在城市
+	EducationLevel  StringFilter `json:"education_level"`  // 本科/职业本科/专科
+	AdmissionBatch  StringFilter `json:"admission_batch"`  // 录取批次，include 不为空时代替 batch 参数
+	TuitionFee      RangeFilter  `json:"tuition_fee"`      // 学费（元/年），设置后排除学费未知的专业
+	StudyDuration   RangeFilter  `json:"study_duration"`   // 学制年数
+	IsNewMajor      *bool        `json:"is_new_major,omitempty"`
+}
+
 // API响应结构
 type Response struct {
 	Code int64  `json:"code"`
@@ -82,11 +157,15 @@ type Response struct {
 	Msg  string `json:"msg"`
 	Rank int64  `json:"rank,omitempty"`
 	Year int    `json:"year,omitempty"`
+	// 考生线差（分数减高考年份控制线），没有控制线时省略
+	StudentLineDiff *int `json:"student_line_diff,omitempty"`
 }
 
 type Data struct {
 	Conf *Conf  `json:"conf,omitempty"`
 	List []List `json:"list"`
+	// 按院校专业组聚合时的报表行，此时 list 为空
+	Groups []GroupItem `json:"groups,omitempty"`
 }
 
 type Conf struct {
@@ -96,24 +175,13 @@ type Conf struct {
 	TotalPage   int64 `json:"total_page"`
 }
 
-// 2024年一分一段表数据结构
-type ScoreRankData struct {
-	Score int `json:"score"` // 分数
-	Rank  int `json:"rank"`  // 排名
-}
-
-// 2024年湖北省一分一段表（硬编码数据）
-type ScoreRankTable2024 struct {
-	Physics []ScoreRankData `json:"physics"` // 物理类
-	History []ScoreRankData `json:"history"` // 历史类
-}
-
 // 新的报表响应结构
 type List struct {
 	ID                       *uint64 `json:"id,omitempty"`
 	CollegeName              *string `json:"college_name,omitempty"`
 	CollegeCode              *string `json:"college_code,omitempty"`
 	SpecialInterestGroupCode *string `json:"special_interest_group_code,omitempty"`
+	MajorCode                *string `json:"major_code,omitempty"`
 	ClassDemand              *string `json:"class_demand,omitempty"`
 	CollegeProvince          *string `json:"college_province,omitempty"`
 	CollegeCity              *string `json:"college_city,omitempty"`
@@ -134,6 +202,100 @@ type List struct {
 	StudyYears        *string `json:"study_years,omitempty"`
 	MajorMinScore2024 *uint16 `json:"major_min_score_2024,omitempty"`
 	MajorMinRank2024  *int    `json:"major_min_rank_2024,omitempty"` // 新增字段：专业最低分对应的2024年排名
+	// 录取最低分换算到考生高考年份的等位分
+	EquivalentScore      *int64 `json:"equivalent_score,omitempty"`       // 专业组最低分的等位分
+	MajorEquivalentScore *int64 `json:"major_equivalent_score,omitempty"` // 专业最低分的等位分
+	// 按录取位次估算的录取概率（0-1）及冲稳保分档，不在任何分档时分档为空
+	AdmissionProbability *float64 `json:"admission_probability,omitempty"`
+	Tier                 string   `json:"tier,omitempty"`
+	Method               string   `json:"method,omitempty"`    // 划分冲稳保的方法：score、rank、line_diff
+	LineDiff             *int     `json:"line_diff,omitempty"` // 专业组最低分减当年控制线
+	// 历年录取数据（按年份降序）
+	History      []YearScore `json:"history,omitempty"`       // 专业组历年最低分和位次
+	MajorHistory []YearScore `json:"major_history,omitempty"` // 专业历年最低分和位次
+}
+
+// 院校专业组报表行，与志愿表的填报单位一致
+type GroupItem struct {
+	CollegeName      string `json:"college_name"`
+	CollegeCode      string `json:"college_code"`
+	MajorGroupCode   string `json:"major_group_code"`
+	ClassDemand      string `json:"class_demand"` // 专业组选科要求
+	CollegeProvince  string `json:"college_province,omitempty"`
+	CollegeCity      string `json:"college_city,omitempty"`
+	CollegeOwnership string `json:"college_ownership,omitempty"`
+	CollegeType      string `json:"college_type,omitempty"`
+	CollegeAuthority string `json:"college_authority,omitempty"`
+	CollegeLevel     string `json:"college_level,omitempty"`
+	CollegeTags      string `json:"college_tags,omitempty"`
+	EducationLevel   string `json:"education_level,omitempty"`
+	LowestPoints     *int64 `json:"lowest_points,omitempty"`    // 专业组最低分
+	LowestRank       *int64 `json:"lowest_rank,omitempty"`      // 专业组最低位次
+	EquivalentScore  *int64 `json:"equivalent_score,omitempty"` // 专业组最低分的等位分
+	EnrollmentPlan   int    `json:"enrollment_plan"`            // 专业组内各专业招生计划数合计
+	// 录取概率、冲稳保分档及线差，含义同报表行
+	AdmissionProbability *float64     `json:"admission_probability,omitempty"`
+	Tier                 string       `json:"tier,omitempty"`
+	Method               string       `json:"method,omitempty"`
+	LineDiff             *int         `json:"line_diff,omitempty"`
+	History              []YearScore  `json:"history,omitempty"` // 专业组历年最低分和位次
+	Majors               []GroupMajor `json:"majors"`            // 按专业最低分降序
+	// 服从调剂风险，按专业组内全部专业计算（不受筛选条件影响）
+	BelowStudentMajors   []string `json:"below_student_majors,omitempty"`  // 专业最低分不高于考生等位分的专业代码
+	DislikedMajors       []string `json:"disliked_majors,omitempty"`       // 属于考生不愿就读方向的专业代码
+	ReassignmentExposure *float64 `json:"reassignment_exposure,omitempty"` // 调剂风险（0-1）
+	ReassignmentRisk     string   `json:"reassignment_risk,omitempty"`     // 调剂风险等级
+}
+
+// 院校专业组标识
+type MajorGroupRef struct {
+	CollegeCode    string `json:"college_code" binding:"required"`
+	MajorGroupCode string `json:"major_group_code" binding:"required"`
+}
+
+// 服从调剂风险分析：考生进入专业组后，没有录取到志愿专业时会被调剂到仍有计划的专业，通常是组内分数最低的专业
+type Reassignment struct {
+	StudentScore int                 `json:"student_score"` // 考生分数换算到2024年的等位分，与专业最低分比较
+	Exposure     float64             `json:"exposure"`      // 调剂风险（0-1）：可能被调剂进入的专业中，不愿就读专业的计划数占比
+	Level        string              `json:"level"`         // 风险等级：低、中、高
+	Majors       []ReassignmentMajor `json:"majors"`        // 按专业最低分升序
+}
+
+// 调剂风险分析中的专业
+type ReassignmentMajor struct {
+	MajorCode         string `json:"major_code"`
+	MajorName         string `json:"major_name"`
+	MajorMinScore2024 uint16 `json:"major_min_score_2024,omitempty"`
+	EnrollmentPlan    int    `json:"enrollment_plan"`
+	BelowStudent      bool   `json:"below_student"`           // 专业最低分不高于考生等位分，可能被调剂进入
+	Disliked          bool   `json:"disliked"`                // 属于考生不愿就读的专业
+	DislikeMatch      string `json:"dislike_match,omitempty"` // 匹配到的不愿就读方向或关键词
+}
+
+// 院校专业组内的专业
+type GroupMajor struct {
+	ID                   uint64      `json:"id"`
+	MajorCode            string      `json:"major_code"`
+	ProfessionalName     string      `json:"professional_name"`
+	ClassDemand          string      `json:"class_demand,omitempty"` // 专业选科要求
+	MajorDescription     string      `json:"major_description,omitempty"`
+	StudyYears           *string     `json:"study_years,omitempty"`
+	TuitionFee           *uint32     `json:"tuition_fee,omitempty"`
+	IsNewMajor           bool        `json:"is_new_major"`
+	EnrollmentPlan       int         `json:"enrollment_plan"` // 招生计划数
+	MajorMinScore2024    *uint16     `json:"major_min_score_2024,omitempty"`
+	MajorMinRank2024     *int        `json:"major_min_rank_2024,omitempty"`
+	MajorEquivalentScore *int64      `json:"major_equivalent_score,omitempty"`
+	MajorHistory         []YearScore `json:"major_history,omitempty"`
+}
+
+// 某一年的最低录取分数和位次
+type YearScore struct {
+	Year            int    `json:"year"`
+	MinScore        uint16 `json:"min_score"`
+	MinRank         uint32 `json:"min_rank,omitempty"`
+	EquivalentScore int    `json:"equivalent_score,omitempty"` // 换算到考生高考年份的等位分
+	LineDiff        *int   `json:"line_diff,omitempty"`        // 最低分减当年控制线
 }
 
 // 位次查询结果
@@ -143,3 +305,186 @@ type RankResponse struct {
 	Rank int64  `json:"rank"`
 	Year int    `json:"year"`
 }
+
+// 志愿表
+type Plan struct {
+	Rank      int64       `json:"rank"`       // 考生位次
+	Score     int64       `json:"score"`      // 考生分数
+	Method    string      `json:"method"`     // 划分冲稳保的方法
+	Slots     int         `json:"slots"`      // 志愿数上限
+	TierRatio string      `json:"tier_ratio"` // 冲稳保比例
+	Quota     PlanQuota   `json:"quota"`      // 按比例分配的各档志愿数
+	Actual    PlanQuota   `json:"actual"`     // 实际填入的各档志愿数
+	Items     []PlanItem  `json:"items"`
+	Warnings  []string    `json:"warnings,omitempty"`
+	Excluded  PlanExclude `json:"excluded"` // 未进入志愿表的专业组数
+}
+
+// 冲稳保各档志愿数
+type PlanQuota struct {
+	Chong int `json:"chong"`
+	Wen   int `json:"wen"`
+	Bao   int `json:"bao"`
+}
+
+// 未进入志愿表的专业组统计
+type PlanExclude struct {
+	Ineligible  int `json:"ineligible"`    // 选科不符合
+	OutOfWindow int `json:"out_of_window"` // 不在冲稳保任何分档
+	SchoolLimit int `json:"school_limit"`  // 超过同一院校的专业组数上限
+}
+
+// 志愿表中的一个院校专业组
+type PlanItem struct {
+	Order                int         `json:"order"` // 志愿序号，从1开始
+	Tier                 string      `json:"tier"`
+	CollegeCode          string      `json:"college_code"`
+	CollegeName          string      `json:"college_name"`
+	MajorGroupCode       string      `json:"major_group_code"`
+	CollegeProvince      string      `json:"college_province,omitempty"`
+	CollegeCity          string      `json:"college_city,omitempty"`
+	ClassDemand          string      `json:"class_demand,omitempty"`
+	LowestPoints         int64       `json:"lowest_points"`
+	LowestRank           int64       `json:"lowest_rank"`
+	AdmissionProbability float64     `json:"admission_probability"`
+	Majors               []PlanMajor `json:"majors"`
+	Explanation          string      `json:"explanation"` // 入选理由
+}
+
+// 专业组内的专业
+type PlanMajor struct {
+	MajorCode  string `json:"major_code"`
+	MajorName  string `json:"major_name"`
+	TuitionFee string `json:"tuition_fee,omitempty"`
+	StudyYears uint8  `json:"study_years,omitempty"`
+}
+
+// 选科组合对比结果，Combinations 的第一项为对比基准
+type CombinationComparison struct {
+	Province     string             `json:"province"`
+	Batch        string             `json:"batch"`
+	Combinations []CombinationStats `json:"combinations"`
+}
+
+// 某个选科组合可报考的专业统计
+type CombinationStats struct {
+	Combination     string             `json:"combination"`      // 请求中的写法
+	Subjects        []string           `json:"subjects"`         // 选考科目，含首选科目
+	SubjectCategory string             `json:"subject_category"` // 对应的科类
+	MajorCount      int                `json:"major_count"`      // 选科符合要求的专业数
+	GroupCount      int                `json:"group_count"`      // 组内专业选科都符合要求、可以填报的专业组数
+	ByCategory      []CombinationItem  `json:"by_category"`      // 按学科门类
+	ByTier          []CombinationItem  `json:"by_tier"`          // 按院校水平层次
+	ByProvince      []CombinationItem  `json:"by_province"`      // 按院校所在省份
+	GainedCount     int                `json:"gained_count"`     // 比基准组合多出的专业数
+	LostCount       int                `json:"lost_count"`       // 比基准组合少的专业数
+	Gained          []CombinationMajor `json:"gained"`
+	Lost            []CombinationMajor `json:"lost"`
+}
+
+// 选科组合统计的一个分组
+type CombinationItem struct {
+	Name       string `json:"name"`
+	MajorCount int    `json:"major_count"`
+	GroupCount int    `json:"group_count"` // 含该分组专业的可填报专业组数
+}
+
+// 选科组合之间有差别的专业，院校代码和专业代码相同视为同一专业
+type CombinationMajor struct {
+	SchoolCode            string `json:"school_code"`
+	SchoolName            string `json:"school_name"`
+	MajorCode             string `json:"major_code"`
+	MajorName             string `json:"major_name"`
+	SubjectRequirementRaw string `json:"subject_requirement_raw"`
+}
+
+// 院校详情：院校信息及其在某科类、批次的全部专业组和专业
+type SchoolDetail struct {
+	SchoolCode      string      `json:"school_code"`
+	SchoolName      string      `json:"school_name"`
+	SchoolProvince  string      `json:"school_province"`
+	SchoolCity      string      `json:"school_city"`
+	SchoolOwnership string      `json:"school_ownership"`
+	SchoolAuthority string      `json:"school_authority"`
+	SchoolLevel     string      `json:"school_level"`
+	SchoolTags      string      `json:"school_tags"`
+	SchoolType      string      `json:"school_type"`
+	SubjectCategory string      `json:"subject_category"`
+	Batch           string      `json:"batch"`
+	MajorCount      int         `json:"major_count"`
+	EnrollmentPlan  int         `json:"enrollment_plan"` // 各专业招生计划数合计
+	Groups          []GroupItem `json:"groups"`          // 按专业组代码升序，组内专业按专业最低分降序
+}
+
+// 按名称搜索到的专业
+type MajorSummary struct {
+	MajorCode   string `json:"major_code"`
+	MajorName   string `json:"major_name"`
+	SchoolCount int    `json:"school_count"` // 开设该专业的院校数
+	GroupCount  int    `json:"group_count"`  // 含该专业的院校专业组数
+}
+
+// 专业详情：开设该专业的全部院校专业组
+type MajorDetail struct {
+	MajorCode       string           `json:"major_code"`
+	MajorName       string           `json:"major_name"`
+	SubjectCategory string           `json:"subject_category"`
+	Batch           string           `json:"batch"`
+	Total           int              `json:"total"`
+	Student         *StudentPosition `json:"student,omitempty"` // 传入位次或分数时返回
+	Offerings       []MajorOffering  `json:"offerings"`
+}
+
+// 开设某专业的院校专业组
+type MajorOffering struct {
+	SchoolCode        string  `json:"school_code"`
+	SchoolName        string  `json:"school_name"`
+	MajorGroupCode    string  `json:"major_group_code"`
+	MajorName         string  `json:"major_name"`
+	ClassDemand       string  `json:"class_demand"`
+	SchoolProvince    string  `json:"school_province"`
+	SchoolCity        string  `json:"school_city"`
+	SchoolLevel       string  `json:"school_level"`
+	SchoolTags        string  `json:"school_tags"`
+	StudyYears        *string `json:"study_years,omitempty"`
+	TuitionFee        *uint32 `json:"tuition_fee,omitempty"`
+	EnrollmentPlan    int     `json:"enrollment_plan"`
+	MajorMinScore2024 *uint16 `json:"major_min_score_2024,omitempty"`
+	MajorMinRank2024  *int    `json:"major_min_rank_2024,omitempty"` // 专业最低分对应的位次
+	GroupMinScore2024 *int64  `json:"group_min_score_2024,omitempty"`
+	GroupMinRank2024  *int64  `json:"group_min_rank_2024,omitempty"`
+	Reachable         *bool   `json:"reachable,omitempty"` // 考生位次不低于录取位次，传入位次或分数时返回
+}
+
+// 考生位次在录取位次分布中的位置，录取位次优先使用专业最低分对应的位次，没有时使用专业组最低位次
+type StudentPosition struct {
+	Rank       int64   `json:"rank"`
+	Compared   int     `json:"compared"`    // 有录取位次的院校专业组数
+	Reachable  int     `json:"reachable"`   // 其中录取位次不前于考生位次的个数
+	Percentile float64 `json:"percentile"`  // reachable / compared
+	BestRank   int64   `json:"best_rank"`   // 录取位次最靠前的
+	MedianRank int64   `json:"median_rank"` // 录取位次中位数
+	WorstRank  int64   `json:"worst_rank"`  // 录取位次最靠后的
+}
+
+// 院校专业组对比结果：Columns 与请求中的专业组顺序一致，Rows 每行是一个对比项，Values 与 Columns 按下标对应
+type GroupComparison struct {
+	Rank    int64             `json:"rank"` // 考生位次
+	Columns []ComparisonGroup `json:"columns"`
+	Rows    []ComparisonRow   `json:"rows"`
+	Groups  []*GroupItem      `json:"groups"` // 各专业组的完整数据，查不到的为null
+}
+
+// 对比的院校专业组
+type ComparisonGroup struct {
+	MajorGroupRef
+	CollegeName string `json:"college_name,omitempty"`
+	Found       bool   `json:"found"` // 是否查到该专业组
+}
+
+// 对比项，查不到的专业组取值为null
+type ComparisonRow struct {
+	Key    string        `json:"key"`
+	Label  string        `json:"label"`
+	Values []interface{} `json:"values"`
+}
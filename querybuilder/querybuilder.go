@@ -0,0 +1,215 @@
+// Package querybuilder 构建参数化的ClickHouse查询语句。
+// 条件和子句中的取值一律用 ? 占位，生成语句时按出现顺序编号为 $1、$2……，
+// 取值作为查询参数传给驱动，不会拼接进SQL文本
+package querybuilder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cond SQL片段及其 ? 占位符对应的参数，? 只能用作占位符
+type Cond struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Expr 创建SQL片段，占位符个数与参数个数不一致时panic（属于编码错误）
+func Expr(sql string, args ...interface{}) Cond {
+	if n := strings.Count(sql, "?"); n != len(args) {
+		panic(fmt.Sprintf("querybuilder: %q 有%d个占位符，参数为%d个", sql, n, len(args)))
+	}
+	return Cond{SQL: sql, Args: args}
+}
+
+// Eq column = ?
+func Eq(column string, value interface{}) Cond {
+	return Expr(column+" = ?", value)
+}
+
+// In 取值属于 values 之一：has(?, column)，values 为切片
+func In(column string, values interface{}) Cond {
+	return Expr("has(?, "+column+")", values)
+}
+
+// NotIn 取值不属于 values：NOT has(?, column)
+func NotIn(column string, values interface{}) Cond {
+	return Expr("NOT has(?, "+column+")", values)
+}
+
+// Between column BETWEEN ? AND ?
+func Between(column string, min, max interface{}) Cond {
+	return Expr(column+" BETWEEN ? AND ?", min, max)
+}
+
+// Contains 字符串字段包含 s：column LIKE ?，s 中的通配符按普通字符匹配
+func Contains(column, s string) Cond {
+	return Expr(column+" LIKE ?", "%"+EscapeLike(s)+"%")
+}
+
+// EscapeLike 转义LIKE模式中的 %、_ 和反斜杠
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Ident 将数据库名、表名等无法作为参数绑定的标识符加反引号转义
+func Ident(name string) string {
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(name) + "`"
+}
+
+// And 用AND连接条件并加括号，没有条件时返回空片段
+func And(conds ...Cond) Cond {
+	return join(" AND ", conds)
+}
+
+// Or 用OR连接条件并加括号，没有条件时返回空片段
+func Or(conds ...Cond) Cond {
+	return join(" OR ", conds)
+}
+
+func join(sep string, conds []Cond) Cond {
+	var parts []string
+	var args []interface{}
+	for _, c := range conds {
+		if c.SQL == "" {
+			continue
+		}
+		parts = append(parts, c.SQL)
+		args = append(args, c.Args...)
+	}
+	switch len(parts) {
+	case 0:
+		return Cond{}
+	case 1:
+		return Cond{SQL: parts[0], Args: args}
+	}
+	return Cond{SQL: "(" + strings.Join(parts, sep) + ")", Args: args}
+}
+
+// SelectBuilder SELECT语句构建器，列名、表名等标识符由调用方保证来自代码而不是请求参数
+type SelectBuilder struct {
+	columns []string
+	from    Cond
+	where   []Cond
+	groupBy []string
+	orderBy []Cond
+	limit   *Cond
+	offset  *Cond
+}
+
+// Select 创建SELECT语句
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns}
+}
+
+// From 数据来源，可以是表名、JOIN子句（可带占位符）
+func (s *SelectBuilder) From(table string, args ...interface{}) *SelectBuilder {
+	s.from = Expr(table, args...)
+	return s
+}
+
+// FromSubquery 以子查询作为数据来源
+func (s *SelectBuilder) FromSubquery(sub *SelectBuilder) *SelectBuilder {
+	c := sub.Cond()
+	s.from = Cond{SQL: "(" + c.SQL + ")", Args: c.Args}
+	return s
+}
+
+// Where 追加WHERE条件，多次调用之间用AND连接，空片段忽略
+func (s *SelectBuilder) Where(conds ...Cond) *SelectBuilder {
+	for _, c := range conds {
+		if c.SQL != "" {
+			s.where = append(s.where, c)
+		}
+	}
+	return s
+}
+
+// GroupBy GROUP BY子句
+func (s *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	s.groupBy = append(s.groupBy, columns...)
+	return s
+}
+
+// OrderBy ORDER BY子句，排序表达式可带占位符
+func (s *SelectBuilder) OrderBy(expr string, args ...interface{}) *SelectBuilder {
+	s.orderBy = append(s.orderBy, Expr(expr, args...))
+	return s
+}
+
+// Limit LIMIT子句
+func (s *SelectBuilder) Limit(n int64) *SelectBuilder {
+	c := Expr("?", n)
+	s.limit = &c
+	return s
+}
+
+// Offset OFFSET子句，需与 Limit 一起使用
+func (s *SelectBuilder) Offset(n int64) *SelectBuilder {
+	c := Expr("?", n)
+	s.offset = &c
+	return s
+}
+
+// Cond 返回带 ? 占位符的语句，用于嵌套为子查询
+func (s *SelectBuilder) Cond() Cond {
+	var parts []string
+	var args []interface{}
+	add := func(keyword string, c Cond) {
+		parts = append(parts, keyword+c.SQL)
+		args = append(args, c.Args...)
+	}
+
+	add("SELECT ", Cond{SQL: strings.Join(s.columns, ", ")})
+	if s.from.SQL != "" {
+		add("FROM ", s.from)
+	}
+	if len(s.where) > 0 {
+		var conds []string
+		for _, c := range s.where {
+			conds = append(conds, c.SQL)
+			args = append(args, c.Args...)
+		}
+		parts = append(parts, "WHERE "+strings.Join(conds, " AND "))
+	}
+	if len(s.groupBy) > 0 {
+		parts = append(parts, "GROUP BY "+strings.Join(s.groupBy, ", "))
+	}
+	if len(s.orderBy) > 0 {
+		var exprs []string
+		for _, c := range s.orderBy {
+			exprs = append(exprs, c.SQL)
+			args = append(args, c.Args...)
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(exprs, ", "))
+	}
+	if s.limit != nil {
+		add("LIMIT ", *s.limit)
+	}
+	if s.limit != nil && s.offset != nil {
+		add("OFFSET ", *s.offset)
+	}
+	return Cond{SQL: strings.Join(parts, " "), Args: args}
+}
+
+// Build 生成SQL和查询参数，? 占位符按出现顺序编号为 $1、$2……
+func (s *SelectBuilder) Build() (string, []interface{}) {
+	c := s.Cond()
+	return Number(c.SQL), c.Args
+}
+
+// Number 将 ? 占位符按出现顺序替换为 $1、$2……
+func Number(sql string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range sql {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
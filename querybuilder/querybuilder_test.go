@@ -0,0 +1,142 @@
+package querybuilder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		cond Cond
+		sql  string
+		args []interface{}
+	}{
+		{"eq", Eq("source_province", "湖北"), "source_province = ?", []interface{}{"湖北"}},
+		{"in", In("admission_batch", []string{"本科批"}), "has(?, admission_batch)", []interface{}{[]string{"本科批"}}},
+		{"not in", NotIn("school_level", []string{"民办"}), "NOT has(?, school_level)", []interface{}{[]string{"民办"}}},
+		{"between", Between("min_rank_2024", int64(100), int64(200)), "min_rank_2024 BETWEEN ? AND ?", []interface{}{int64(100), int64(200)}},
+		{"contains", Contains("major_name", "计算机"), "major_name LIKE ?", []interface{}{"%计算机%"}},
+		{"contains escapes wildcards", Contains("major_name", `100%_\`), "major_name LIKE ?", []interface{}{`%100\%\_\\%`}},
+		{"and", And(Eq("a", 1), Eq("b", 2)), "(a = ? AND b = ?)", []interface{}{1, 2}},
+		{"or", Or(Eq("a", 1), Expr("b = true")), "(a = ? OR b = true)", []interface{}{1}},
+		{"or single", Or(Eq("a", 1)), "a = ?", []interface{}{1}},
+		{"or skips empty", Or(Cond{}, Eq("a", 1), Cond{}), "a = ?", []interface{}{1}},
+		{"or empty", Or(), "", nil},
+		{"nested", And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3))), "(a = ? AND (b = ? OR c = ?))", []interface{}{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cond.SQL != tt.sql {
+				t.Errorf("SQL = %q, want %q", tt.cond.SQL, tt.sql)
+			}
+			if !reflect.DeepEqual(tt.cond.Args, tt.args) {
+				t.Errorf("Args = %#v, want %#v", tt.cond.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestExprPanicsOnArgumentMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expr did not panic")
+		}
+	}()
+	Expr("a = ? AND b = ?", 1)
+}
+
+func TestSelectBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *SelectBuilder
+		sql   string
+		args  []interface{}
+	}{
+		{
+			name:  "columns only",
+			query: Select("count()").From("gaokao2025"),
+			sql:   "SELECT count() FROM gaokao2025",
+		},
+		{
+			name:  "empty where ignored",
+			query: Select("id").From("gaokao2025").Where(Cond{}, Or()),
+			sql:   "SELECT id FROM gaokao2025",
+		},
+		{
+			name: "where order limit",
+			query: Select("id", "major_name").
+				From("gaokao2025").
+				Where(Eq("source_province", "湖北"), In("admission_batch", []string{"本科批"})).
+				Where(Between("min_score_2024", 600, 620)).
+				OrderBy("min_score_2024 DESC").
+				Limit(10).
+				Offset(20),
+			sql:  "SELECT id, major_name FROM gaokao2025 WHERE source_province = $1 AND has($2, admission_batch) AND min_score_2024 BETWEEN $3 AND $4 ORDER BY min_score_2024 DESC LIMIT $5 OFFSET $6",
+			args: []interface{}{"湖北", []string{"本科批"}, 600, 620, int64(10), int64(20)},
+		},
+		{
+			name:  "offset without limit ignored",
+			query: Select("id").From("gaokao2025").Offset(5),
+			sql:   "SELECT id FROM gaokao2025",
+		},
+		{
+			name: "from with placeholder is numbered first",
+			query: Select("h_min_score").
+				From("gaokao2025 AS g INNER JOIN (SELECT * FROM admission_history WHERE year = ?) AS h ON g.id = h.id", 2023).
+				Where(Eq("source_province", "湖北")),
+			sql:  "SELECT h_min_score FROM gaokao2025 AS g INNER JOIN (SELECT * FROM admission_history WHERE year = $1) AS h ON g.id = h.id WHERE source_province = $2",
+			args: []interface{}{2023, "湖北"},
+		},
+		{
+			name: "group by and order by with placeholder",
+			query: Select("concat(school_code, '|', major_group_code) AS group_key").
+				From("gaokao2025").
+				Where(Eq("subject_category", "物理")).
+				GroupBy("group_key").
+				OrderBy("indexOf(?, group_key)", []string{"10487|01"}).
+				OrderBy("group_key"),
+			sql:  "SELECT concat(school_code, '|', major_group_code) AS group_key FROM gaokao2025 WHERE subject_category = $1 GROUP BY group_key ORDER BY indexOf($2, group_key), group_key",
+			args: []interface{}{"物理", []string{"10487|01"}},
+		},
+		{
+			name: "subquery",
+			query: Select("COUNT(*)").FromSubquery(
+				Select("school_code").From("gaokao2025").Where(Eq("source_province", "湖北")).GroupBy("school_code"),
+			).Where(Expr("school_code != ?", "")),
+			sql:  "SELECT COUNT(*) FROM (SELECT school_code FROM gaokao2025 WHERE source_province = $1 GROUP BY school_code) WHERE school_code != $2",
+			args: []interface{}{"湖北", ""},
+		},
+		{
+			name:  "values never reach the SQL text",
+			query: Select("id").From("admission_data").Where(Eq("province", "湖北' OR '1'='1")),
+			sql:   "SELECT id FROM admission_data WHERE province = $1",
+			args:  []interface{}{"湖北' OR '1'='1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.query.Build()
+			if sql != tt.sql {
+				t.Errorf("SQL = %q, want %q", sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("Args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestIdent(t *testing.T) {
+	tests := map[string]string{
+		"gaokao":       "`gaokao`",
+		"a`b":          "`a\\`b`",
+		`x\`:           "`x\\\\`",
+		"db; DROP foo": "`db; DROP foo`",
+	}
+	for name, want := range tests {
+		if got := Ident(name); got != want {
+			t.Errorf("Ident(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package recommend
+
+import (
+	"sort"
+
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+// CombinationInput 待对比的选科组合，Rows 为该组合所属科类的全部录取数据
+type CombinationInput struct {
+	Name            string
+	SubjectCategory string
+	Subjects        []string // 选考科目，含首选科目
+	Rows            []models.AdmissionHubeiWide
+}
+
+// CompareCombinations 统计每个选科组合可报考的专业和专业组，按学科门类、院校水平层次和院校所在省份分组，
+// 并与第一个组合对比多出和少的专业，差异专业最多列出 limit 个
+func CompareCombinations(tax *taxonomy.Taxonomy, inputs []CombinationInput, limit int) []models.CombinationStats {
+	result := make([]models.CombinationStats, len(inputs))
+	var baseline map[string]*models.AdmissionHubeiWide
+	for i, in := range inputs {
+		stats, eligible := combinationStats(tax, in)
+		stats.Gained, stats.Lost = []models.CombinationMajor{}, []models.CombinationMajor{}
+		if i == 0 {
+			baseline = eligible
+		} else {
+			stats.Gained, stats.GainedCount = diffMajors(eligible, baseline, limit)
+			stats.Lost, stats.LostCount = diffMajors(baseline, eligible, limit)
+		}
+		result[i] = stats
+	}
+	return result
+}
+
+// 专业组内各专业的选科情况
+type combinationGroup struct {
+	eligible    bool
+	tier        string
+	province    string
+	disciplines map[string]bool
+}
+
+// 统计一个选科组合，返回统计结果和选科符合要求的专业（院校代码+专业代码 -> 录取数据）
+func combinationStats(tax *taxonomy.Taxonomy, in CombinationInput) (models.CombinationStats, map[string]*models.AdmissionHubeiWide) {
+	// 3+1+2 省份首选科目即科类，综合改革省份全部科目都是再选科目
+	first := ""
+	var optional []string
+	for _, subject := range in.Subjects {
+		if subject == in.SubjectCategory {
+			first = subject
+		} else {
+			optional = append(optional, subject)
+		}
+	}
+
+	eligible := make(map[string]*models.AdmissionHubeiWide)
+	groups := make(map[string]*combinationGroup)
+	var order []string
+	for i := range in.Rows {
+		row := &in.Rows[i]
+		key := row.SchoolCode + "|" + row.MajorGroupCode
+		g, ok := groups[key]
+		if !ok {
+			g = &combinationGroup{eligible: true, tier: valueOr(row.SchoolLevel, "未知"), province: valueOr(row.SchoolProvince, "未知"), disciplines: make(map[string]bool)}
+			groups[key] = g
+			order = append(order, key)
+		}
+		discipline := disciplineName(tax, row)
+		g.disciplines[discipline] = true
+		if !database.SubjectEligible(row, first, optional) {
+			g.eligible = false
+			continue
+		}
+		eligible[majorKey(row)] = row
+	}
+
+	stats := models.CombinationStats{
+		Combination:     in.Name,
+		Subjects:        in.Subjects,
+		SubjectCategory: in.SubjectCategory,
+		MajorCount:      len(eligible),
+	}
+	byCategory := newCombinationCounter()
+	byTier := newCombinationCounter()
+	byProvince := newCombinationCounter()
+	for _, row := range eligible {
+		byCategory.major(disciplineName(tax, row))
+		byTier.major(valueOr(row.SchoolLevel, "未知"))
+		byProvince.major(valueOr(row.SchoolProvince, "未知"))
+	}
+	for _, key := range order {
+		g := groups[key]
+		if !g.eligible {
+			continue
+		}
+		stats.GroupCount++
+		for discipline := range g.disciplines {
+			byCategory.group(discipline)
+		}
+		byTier.group(g.tier)
+		byProvince.group(g.province)
+	}
+	stats.ByCategory = byCategory.items()
+	stats.ByTier = byTier.items()
+	stats.ByProvince = byProvince.items()
+	return stats, eligible
+}
+
+// 按名称累计专业数和专业组数
+type combinationCounter map[string]*models.CombinationItem
+
+func newCombinationCounter() combinationCounter {
+	return make(combinationCounter)
+}
+
+func (c combinationCounter) get(name string) *models.CombinationItem {
+	item, ok := c[name]
+	if !ok {
+		item = &models.CombinationItem{Name: name}
+		c[name] = item
+	}
+	return item
+}
+
+func (c combinationCounter) major(name string) { c.get(name).MajorCount++ }
+
+func (c combinationCounter) group(name string) { c.get(name).GroupCount++ }
+
+// 按专业数降序
+func (c combinationCounter) items() []models.CombinationItem {
+	items := []models.CombinationItem{}
+	for _, item := range c {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].MajorCount != items[j].MajorCount {
+			return items[i].MajorCount > items[j].MajorCount
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// a 中有、b 中没有的专业，按院校代码和专业代码排序，返回前 limit 个和总数
+func diffMajors(a, b map[string]*models.AdmissionHubeiWide, limit int) ([]models.CombinationMajor, int) {
+	var keys []string
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	majors := []models.CombinationMajor{}
+	for _, key := range keys {
+		if len(majors) >= limit {
+			break
+		}
+		row := a[key]
+		majors = append(majors, models.CombinationMajor{
+			SchoolCode:            row.SchoolCode,
+			SchoolName:            row.SchoolName,
+			MajorCode:             row.MajorCode,
+			MajorName:             row.MajorName,
+			SubjectRequirementRaw: row.SubjectRequirementRaw,
+		})
+	}
+	return majors, len(keys)
+}
+
+// 院校代码+专业代码，不同科类的同一专业视为同一专业；没有专业代码时按专业名称
+func majorKey(row *models.AdmissionHubeiWide) string {
+	return row.SchoolCode + "|" + valueOr(row.MajorCode, row.MajorName)
+}
+
+// 专业所属学科门类，专业代码不属于分类体系（如专科专业）时为其他
+func disciplineName(tax *taxonomy.Taxonomy, row *models.AdmissionHubeiWide) string {
+	if d, ok := tax.DisciplineOf(row.MajorCode); ok {
+		return d.Name
+	}
+	return "其他"
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 对比项：键、名称及从专业组取值的方法，yearly 的名称前加参考录取年份
+var comparisonFields = []struct {
+	key    string
+	label  string
+	yearly bool
+	value  func(g *models.GroupItem) interface{}
+}{
+	{"college_name", "院校名称", false, func(g *models.GroupItem) interface{} { return g.CollegeName }},
+	{"location", "所在地", false, func(g *models.GroupItem) interface{} {
+		return strings.TrimSpace(g.CollegeProvince + " " + g.CollegeCity)
+	}},
+	{"college_level", "院校层次", false, func(g *models.GroupItem) interface{} { return g.CollegeLevel }},
+	{"college_tags", "院校标签", false, func(g *models.GroupItem) interface{} { return g.CollegeTags }},
+	{"college_ownership", "公私性质", false, func(g *models.GroupItem) interface{} { return g.CollegeOwnership }},
+	{"class_demand", "选科要求", false, func(g *models.GroupItem) interface{} { return g.ClassDemand }},
+	{"lowest_points", "专业组最低分", true, func(g *models.GroupItem) interface{} { return g.LowestPoints }},
+	{"lowest_rank", "专业组最低位次", true, func(g *models.GroupItem) interface{} { return g.LowestRank }},
+	{"enrollment_plan", "招生计划数", false, func(g *models.GroupItem) interface{} { return g.EnrollmentPlan }},
+	{"tuition_fee", "学费（元/年）", false, groupTuition},
+	{"major_count", "专业数", false, func(g *models.GroupItem) interface{} { return len(g.Majors) }},
+	{"majors", "专业", false, func(g *models.GroupItem) interface{} {
+		names := make([]string, len(g.Majors))
+		for i, m := range g.Majors {
+			names[i] = m.ProfessionalName
+		}
+		return names
+	}},
+	{"admission_probability", "录取概率", false, func(g *models.GroupItem) interface{} { return g.AdmissionProbability }},
+	{"tier", "冲稳保", false, func(g *models.GroupItem) interface{} { return g.Tier }},
+}
+
+// BuildGroupComparison 按请求顺序对齐院校专业组，每个对比项一行；groups 中没有的专业组取值为null。
+// year 为录取分数线的参考年份
+func BuildGroupComparison(refs []models.MajorGroupRef, groups []models.GroupItem, year int, rank int64) *models.GroupComparison {
+	byRef := make(map[models.MajorGroupRef]*models.GroupItem, len(groups))
+	for i := range groups {
+		byRef[models.MajorGroupRef{CollegeCode: groups[i].CollegeCode, MajorGroupCode: groups[i].MajorGroupCode}] = &groups[i]
+	}
+
+	result := &models.GroupComparison{
+		Rank:    rank,
+		Columns: make([]models.ComparisonGroup, len(refs)),
+		Rows:    make([]models.ComparisonRow, len(comparisonFields)),
+		Groups:  make([]*models.GroupItem, len(refs)),
+	}
+	for i, ref := range refs {
+		result.Columns[i].MajorGroupRef = ref
+		if g, ok := byRef[ref]; ok {
+			result.Columns[i].CollegeName = g.CollegeName
+			result.Columns[i].Found = true
+			result.Groups[i] = g
+		}
+	}
+	for i, field := range comparisonFields {
+		label := field.label
+		if field.yearly {
+			label = fmt.Sprintf("%d年%s", year, label)
+		}
+		row := models.ComparisonRow{Key: field.key, Label: label, Values: make([]interface{}, len(refs))}
+		for j, g := range result.Groups {
+			if g != nil {
+				row.Values[j] = field.value(g)
+			}
+		}
+		result.Rows[i] = row
+	}
+	return result
+}
+
+// 专业组内各专业的学费，不同时为 最低-最高，没有学费数据时为null
+func groupTuition(g *models.GroupItem) interface{} {
+	var low, high uint32
+	for _, m := range g.Majors {
+		if m.TuitionFee == nil {
+			continue
+		}
+		if low == 0 || *m.TuitionFee < low {
+			low = *m.TuitionFee
+		}
+		if *m.TuitionFee > high {
+			high = *m.TuitionFee
+		}
+	}
+	switch {
+	case high == 0:
+		return nil
+	case low == high:
+		return fmt.Sprint(low)
+	default:
+		return fmt.Sprintf("%d-%d", low, high)
+	}
+}
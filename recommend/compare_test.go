@@ -0,0 +1,27 @@
+package recommend
+
+import (
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+func TestBuildGroupComparisonYearLabels(t *testing.T) {
+	refs := []models.MajorGroupRef{{CollegeCode: "10487", MajorGroupCode: "01"}, {CollegeCode: "10486", MajorGroupCode: "02"}}
+	groups := []models.GroupItem{{CollegeCode: "10487", MajorGroupCode: "01", CollegeName: "华中科技大学"}}
+	result := BuildGroupComparison(refs, groups, 2023, 5000)
+
+	labels := make(map[string]string)
+	for _, row := range result.Rows {
+		labels[row.Key] = row.Label
+	}
+	if labels["lowest_points"] != "2023年专业组最低分" || labels["lowest_rank"] != "2023年专业组最低位次" {
+		t.Errorf("labels = %v", labels)
+	}
+	if labels["college_name"] != "院校名称" {
+		t.Errorf("college_name label = %q", labels["college_name"])
+	}
+	if !result.Columns[0].Found || result.Columns[1].Found {
+		t.Errorf("columns = %+v", result.Columns)
+	}
+}
@@ -0,0 +1,118 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+
+	"gaokao-zhiyuan/controlline"
+	"gaokao-zhiyuan/models"
+)
+
+// LineDiffWindows 线差法窗口：冲下限、稳下限、保下限、保上限四个边界，表示考生线差减专业组线差的分差。
+// 冲为 [冲下限, 稳下限)，稳为 [稳下限, 保下限)，保为 [保下限, 保上限]
+type LineDiffWindows struct {
+	Bounds [4]float64
+}
+
+// ParseLineDiffWindows 解析 "冲下限,稳下限,保下限,保上限"，如 "-20,-3,5,20"
+func ParseLineDiffWindows(value string) (LineDiffWindows, error) {
+	bounds, _, err := parseBounds(value, false)
+	if err != nil {
+		return LineDiffWindows{}, err
+	}
+	return LineDiffWindows{Bounds: bounds}, nil
+}
+
+// LineDiffWindow 某考生的线差法窗口。
+// 考生线差按高考年份的控制线计算，专业组线差按参考年份的控制线计算，两个年份的线差直接比较
+type LineDiffWindow struct {
+	LineType        string `json:"line_type"`         // 控制线类型
+	StudentLineDiff int    `json:"student_line_diff"` // 考生线差
+	Line            int    `json:"line"`              // 参考年份控制线
+	Bounds          [4]int64
+}
+
+// LineDiff 计算考生在高考年份的线差，并换算为参考年份的线差法窗口
+func (m *Model) LineDiff(province string, examYear, year int, category, batch, lineType string, score int) (*LineDiffWindow, error) {
+	studentLineDiff, err := m.lines.LineDiff(province, examYear, category, batch, lineType, score)
+	if err != nil {
+		return nil, err
+	}
+	line, ok := m.lines.Get(province, year, category, batch, lineType)
+	if !ok {
+		return nil, fmt.Errorf("没有%d年%s%s类%s%s", year, province, category, batch, lineType)
+	}
+
+	window := &LineDiffWindow{LineType: lineType, StudentLineDiff: studentLineDiff, Line: line.Score}
+	for i, b := range m.LineDiffWindows.Bounds {
+		window.Bounds[i] = int64(math.Round(b))
+	}
+	return window, nil
+}
+
+// Apply 分差 = 考生线差 - (录取分 - 控制线)，按分差区间换算参考年份的录取分范围
+func (w *LineDiffWindow) Apply(q *models.ReportQuery, strategy int) {
+	low, high := boundsRange(w.Bounds, strategy)
+	base := int64(w.Line + w.StudentLineDiff)
+	q.MinCutoffScore, q.MaxCutoffScore = base-high, base-low
+	if q.MinCutoffScore < 1 {
+		q.MinCutoffScore = 1 // 排除缺少录取分的数据
+	}
+}
+
+// Tier 报表行的分差所在的窗口
+func (w *LineDiffWindow) Tier(item *models.List) (Tier, bool) {
+	if item.LowestPoints == nil || *item.LowestPoints <= 0 {
+		return "", false
+	}
+	margin := int64(w.StudentLineDiff) - (*item.LowestPoints - int64(w.Line))
+	return boundsTier(w.Bounds, margin)
+}
+
+// AttachLineDiffs 为报表行及其历年录取数据附加线差，各年份使用当年的控制线，没有控制线的年份不计算
+func AttachLineDiffs(list []models.List, lines *controlline.Set, q *models.ReportQuery, lineType string) {
+	lineDiff := lineDiffFunc(lines, q, lineType)
+	for i := range list {
+		item := &list[i]
+		if item.LowestPoints != nil {
+			item.LineDiff = lineDiff(q.Year, *item.LowestPoints)
+		}
+		attachHistoryLineDiffs(item.History, lineDiff)
+		attachHistoryLineDiffs(item.MajorHistory, lineDiff)
+	}
+}
+
+// AttachGroupLineDiffs 为院校专业组报表行及组内专业的历年录取数据附加线差
+func AttachGroupLineDiffs(groups []models.GroupItem, lines *controlline.Set, q *models.ReportQuery, lineType string) {
+	lineDiff := lineDiffFunc(lines, q, lineType)
+	for i := range groups {
+		group := &groups[i]
+		if group.LowestPoints != nil {
+			group.LineDiff = lineDiff(q.Year, *group.LowestPoints)
+		}
+		attachHistoryLineDiffs(group.History, lineDiff)
+		for j := range group.Majors {
+			attachHistoryLineDiffs(group.Majors[j].MajorHistory, lineDiff)
+		}
+	}
+}
+
+// 某年录取分的线差，没有录取分或控制线时为nil
+func lineDiffFunc(lines *controlline.Set, q *models.ReportQuery, lineType string) func(year int, score int64) *int {
+	return func(year int, score int64) *int {
+		if score <= 0 {
+			return nil
+		}
+		diff, err := lines.LineDiff(q.Province, year, q.ClassFirstChoice, q.Batch, lineType, int(score))
+		if err != nil {
+			return nil
+		}
+		return &diff
+	}
+}
+
+func attachHistoryLineDiffs(history []models.YearScore, lineDiff func(year int, score int64) *int) {
+	for i := range history {
+		history[i].LineDiff = lineDiff(history[i].Year, int64(history[i].MinScore))
+	}
+}
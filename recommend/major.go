@@ -0,0 +1,147 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 专业详情支持的排序字段及默认方向（true为降序）
+var offeringSorts = map[string]struct {
+	desc bool
+	key  func(o *models.MajorOffering) (int64, bool)
+}{
+	"score":   {true, offeringScore},
+	"rank":    {false, OfferingCutoffRank},
+	"tuition": {false, offeringTuition},
+}
+
+// SortOfferings 按 score（专业最低分，没有时用专业组最低分）、rank（录取位次）或 tuition（学费）排序，
+// order 为 asc/desc，为空时分数降序、位次和学费升序；缺少该字段的排在最后
+func SortOfferings(offerings []models.MajorOffering, by, order string) error {
+	if by == "" {
+		by = "score"
+	}
+	s, ok := offeringSorts[by]
+	if !ok {
+		return fmt.Errorf("不支持的排序字段: %s", by)
+	}
+	desc := s.desc
+	switch order {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return fmt.Errorf("不支持的排序方向: %s", order)
+	}
+
+	sort.SliceStable(offerings, func(i, j int) bool {
+		a, aok := s.key(&offerings[i])
+		b, bok := s.key(&offerings[j])
+		if aok != bok {
+			return aok
+		}
+		if a != b {
+			return (a > b) == desc
+		}
+		if offerings[i].SchoolCode != offerings[j].SchoolCode {
+			return offerings[i].SchoolCode < offerings[j].SchoolCode
+		}
+		return offerings[i].MajorGroupCode < offerings[j].MajorGroupCode
+	})
+	return nil
+}
+
+// OfferingCutoffRank 录取位次：专业最低分对应的位次，没有时使用专业组最低位次
+func OfferingCutoffRank(o *models.MajorOffering) (int64, bool) {
+	if o.MajorMinRank2024 != nil && *o.MajorMinRank2024 > 0 {
+		return int64(*o.MajorMinRank2024), true
+	}
+	if o.GroupMinRank2024 != nil {
+		return *o.GroupMinRank2024, true
+	}
+	return 0, false
+}
+
+func offeringScore(o *models.MajorOffering) (int64, bool) {
+	if o.MajorMinScore2024 != nil {
+		return int64(*o.MajorMinScore2024), true
+	}
+	if o.GroupMinScore2024 != nil {
+		return *o.GroupMinScore2024, true
+	}
+	return 0, false
+}
+
+func offeringTuition(o *models.MajorOffering) (int64, bool) {
+	if o.TuitionFee == nil {
+		return 0, false
+	}
+	return int64(*o.TuitionFee), true
+}
+
+// LocateStudent 计算考生位次在录取位次分布中的位置，并标记每个院校专业组考生位次是否达到录取位次
+func LocateStudent(offerings []models.MajorOffering, rank int64) *models.StudentPosition {
+	position := &models.StudentPosition{Rank: rank}
+	var cutoffs []int64
+	for i := range offerings {
+		cutoff, ok := OfferingCutoffRank(&offerings[i])
+		if !ok {
+			continue
+		}
+		reachable := rank <= cutoff
+		offerings[i].Reachable = &reachable
+		if reachable {
+			position.Reachable++
+		}
+		cutoffs = append(cutoffs, cutoff)
+	}
+
+	position.Compared = len(cutoffs)
+	if len(cutoffs) == 0 {
+		return position
+	}
+	sort.Slice(cutoffs, func(i, j int) bool { return cutoffs[i] < cutoffs[j] })
+	position.Percentile = math.Round(float64(position.Reachable)/float64(len(cutoffs))*1000) / 1000
+	position.BestRank = cutoffs[0]
+	position.MedianRank = cutoffs[len(cutoffs)/2]
+	position.WorstRank = cutoffs[len(cutoffs)-1]
+	return position
+}
+
+// SummarizeMajors 按专业代码和名称汇总搜索结果，按开设院校数降序
+func SummarizeMajors(rows []models.AdmissionHubeiWide) []models.MajorSummary {
+	summaries := []models.MajorSummary{}
+	index := make(map[string]int)
+	schools := make(map[string]bool)
+	groups := make(map[string]bool)
+	for i := range rows {
+		row := &rows[i]
+		key := row.MajorCode + "|" + row.MajorName
+		idx, ok := index[key]
+		if !ok {
+			idx = len(summaries)
+			index[key] = idx
+			summaries = append(summaries, models.MajorSummary{MajorCode: row.MajorCode, MajorName: row.MajorName})
+		}
+		if school := key + "|" + row.SchoolCode; !schools[school] {
+			schools[school] = true
+			summaries[idx].SchoolCount++
+		}
+		if group := key + "|" + row.SchoolCode + "|" + row.MajorGroupCode; !groups[group] {
+			groups[group] = true
+			summaries[idx].GroupCount++
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].SchoolCount != summaries[j].SchoolCount {
+			return summaries[i].SchoolCount > summaries[j].SchoolCount
+		}
+		return summaries[i].MajorCode < summaries[j].MajorCode
+	})
+	return summaries
+}
@@ -0,0 +1,291 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+)
+
+// 冲稳保分档顺序，从难到易
+var tierOrder = []Tier{TierChong, TierWen, TierBao}
+
+// TierRatio 志愿表中冲稳保各档志愿数的比例
+type TierRatio [3]int
+
+// ParseTierRatio 解析 "冲:稳:保"，如 "3:4:3"
+func ParseTierRatio(value string) (TierRatio, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return TierRatio{}, fmt.Errorf("冲稳保比例格式错误: %s（应为 冲:稳:保，如 3:4:3）", value)
+	}
+	var r TierRatio
+	total := 0
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v < 0 {
+			return TierRatio{}, fmt.Errorf("冲稳保比例格式错误: %s", value)
+		}
+		r[i] = v
+		total += v
+	}
+	if total == 0 {
+		return TierRatio{}, fmt.Errorf("冲稳保比例不能全为0: %s", value)
+	}
+	return r, nil
+}
+
+func (r TierRatio) String() string {
+	return fmt.Sprintf("%d:%d:%d", r[0], r[1], r[2])
+}
+
+// Quota 按比例分配志愿数，向下取整后剩余的志愿依次分给保、稳、冲
+func (r TierRatio) Quota(slots int) [3]int {
+	total := r[0] + r[1] + r[2]
+	var quota [3]int
+	assigned := 0
+	for i, v := range r {
+		quota[i] = slots * v / total
+		assigned += quota[i]
+	}
+	for i := len(quota) - 1; assigned < slots; i = (i + len(quota) - 1) % len(quota) {
+		if r[i] > 0 {
+			quota[i]++
+			assigned++
+		}
+	}
+	return quota
+}
+
+// PlanOptions 志愿表生成参数
+type PlanOptions struct {
+	Year               int       // 参考录取年份
+	Slots              int       // 志愿数上限
+	Ratio              TierRatio // 冲稳保比例
+	MaxGroupsPerSchool int       // 同一院校最多填报的专业组数，为0时不限
+	// 考生是否满足专业的选科要求，专业组内任一专业不满足时整个专业组不填报
+	Eligible func(row *models.AdmissionHubeiWide) bool
+	// 候选专业组内的全部专业（不受兴趣方向、专业类等筛选条件影响），用于判断选科要求，
+	// 为空时只按 rows 中的专业判断
+	GroupMajors []models.AdmissionHubeiWide
+}
+
+// 院校专业组候选
+type planGroup struct {
+	rows        []models.AdmissionHubeiWide
+	tier        Tier
+	probability float64
+}
+
+func (g *planGroup) first() *models.AdmissionHubeiWide { return &g.rows[0] }
+
+func (g *planGroup) rank() int64 { return int64(g.first().MinRank2024) }
+
+// BuildPlan 从符合条件的录取数据生成志愿表：
+// 按院校专业组去重，排除选科不符合的专业组，按窗口划分冲稳保后按比例挑选，
+// 某一档候选不足时由更稳妥的分档补足，最后按录取位次从难到易排序
+func BuildPlan(rows []models.AdmissionHubeiWide, estimate *Estimate, window Window, method Method, opts PlanOptions) *models.Plan {
+	plan := &models.Plan{
+		Rank:      int64(estimate.Rank),
+		Method:    string(method),
+		Slots:     opts.Slots,
+		TierRatio: opts.Ratio.String(),
+		Items:     []models.PlanItem{},
+	}
+
+	// 按院校专业组聚合，同一专业组只填报一次
+	groups := make(map[string]*planGroup)
+	var keys []string
+	for _, row := range rows {
+		key := row.SchoolCode + "|" + row.MajorGroupCode
+		g, ok := groups[key]
+		if !ok {
+			g = &planGroup{}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	// 选科要求按专业组内的全部专业判断，被专业筛选条件过滤掉的专业同样要满足
+	members := make(map[string][]models.AdmissionHubeiWide)
+	for _, row := range opts.GroupMajors {
+		key := row.SchoolCode + "|" + row.MajorGroupCode
+		members[key] = append(members[key], row)
+	}
+
+	candidates := make(map[Tier][]*planGroup)
+	for _, key := range keys {
+		g := groups[key]
+		if opts.Eligible != nil && (!allEligible(g.rows, opts.Eligible) || !allEligible(members[key], opts.Eligible)) {
+			plan.Excluded.Ineligible++
+			continue
+		}
+		score, rank := int64(g.first().MinScore2024), g.rank()
+		tier, ok := window.Tier(&models.List{LowestPoints: &score, LowestRank: &rank})
+		if !ok || rank <= 0 {
+			plan.Excluded.OutOfWindow++
+			continue
+		}
+		g.tier = tier
+		g.probability = math.Round(estimate.Probability(rank)*1000) / 1000
+		candidates[tier] = append(candidates[tier], g)
+	}
+	for _, tier := range tierOrder {
+		list := candidates[tier]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].rank() < list[j].rank() })
+	}
+
+	quota := opts.Ratio.Quota(opts.Slots)
+	plan.Quota = models.PlanQuota{Chong: quota[0], Wen: quota[1], Bao: quota[2]}
+
+	perSchool := make(map[string]int)
+	picked := make(map[*planGroup]bool)
+	notes := make(map[*planGroup]string)
+	schoolLimited := make(map[*planGroup]bool)
+	take := func(g *planGroup) bool {
+		if picked[g] {
+			return false
+		}
+		if opts.MaxGroupsPerSchool > 0 && perSchool[g.first().SchoolCode] >= opts.MaxGroupsPerSchool {
+			schoolLimited[g] = true
+			return false
+		}
+		picked[g] = true
+		perSchool[g.first().SchoolCode]++
+		return true
+	}
+
+	// 各档按比例挑选，候选在录取位次上均匀分布，不足的名额顺延到下一档
+	carry := 0
+	for i, tier := range tierOrder {
+		want := quota[i] + carry
+		got := 0
+		for _, g := range spread(candidates[tier], want) {
+			if got == want {
+				break
+			}
+			if take(g) {
+				got++
+				if carry > 0 && got > quota[i] {
+					notes[g] = fmt.Sprintf("%s档候选不足，由%s档补位", tierOrder[i-1], tier)
+				}
+			}
+		}
+		carry = want - got
+		if got < quota[i] {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s档符合条件的专业组只有%d个，少于计划的%d个", tier, got, quota[i]))
+		}
+	}
+	// 保档仍然不足时，用稳档、冲档剩余的候选补足
+	for i := len(tierOrder) - 2; i >= 0 && carry > 0; i-- {
+		for _, g := range candidates[tierOrder[i]] {
+			if carry == 0 {
+				break
+			}
+			if take(g) {
+				carry--
+				notes[g] = fmt.Sprintf("保档候选不足，由%s档补位", tierOrder[i])
+			}
+		}
+	}
+	if carry > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("符合条件的专业组不足，志愿表只填了%d个，空余%d个", opts.Slots-carry, carry))
+	}
+	plan.Excluded.SchoolLimit = len(schoolLimited)
+	for g := range schoolLimited {
+		if picked[g] {
+			plan.Excluded.SchoolLimit--
+		}
+	}
+
+	var selected []*planGroup
+	for _, tier := range tierOrder {
+		for _, g := range candidates[tier] {
+			if picked[g] {
+				selected = append(selected, g)
+			}
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].rank() < selected[j].rank() })
+
+	for i, g := range selected {
+		item := planItem(g, estimate, opts.Year, notes[g])
+		item.Order = i + 1
+		plan.Items = append(plan.Items, item)
+		switch g.tier {
+		case TierChong:
+			plan.Actual.Chong++
+		case TierWen:
+			plan.Actual.Wen++
+		case TierBao:
+			plan.Actual.Bao++
+		}
+	}
+	return plan
+}
+
+// 从按录取位次排序的候选中挑选n个均匀分布的专业组排在前面，其余按原顺序排在后面作为备选
+func spread(list []*planGroup, n int) []*planGroup {
+	if n <= 0 || n >= len(list) {
+		return list
+	}
+	ordered := make([]*planGroup, 0, len(list))
+	used := make([]bool, len(list))
+	for i := 0; i < n; i++ {
+		idx := (2*i + 1) * len(list) / (2 * n)
+		if !used[idx] {
+			used[idx] = true
+			ordered = append(ordered, list[idx])
+		}
+	}
+	for i, g := range list {
+		if !used[i] {
+			ordered = append(ordered, g)
+		}
+	}
+	return ordered
+}
+
+func allEligible(rows []models.AdmissionHubeiWide, eligible func(row *models.AdmissionHubeiWide) bool) bool {
+	for i := range rows {
+		if !eligible(&rows[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func planItem(g *planGroup, estimate *Estimate, year int, note string) models.PlanItem {
+	first := g.first()
+	item := models.PlanItem{
+		Tier:                 string(g.tier),
+		CollegeCode:          first.SchoolCode,
+		CollegeName:          first.SchoolName,
+		MajorGroupCode:       first.MajorGroupCode,
+		CollegeProvince:      first.SchoolProvince,
+		CollegeCity:          first.SchoolCity,
+		ClassDemand:          first.SubjectRequirementRaw,
+		LowestPoints:         int64(first.MinScore2024),
+		LowestRank:           g.rank(),
+		AdmissionProbability: g.probability,
+	}
+	for _, row := range g.rows {
+		item.Majors = append(item.Majors, models.PlanMajor{
+			MajorCode:  row.MajorCode,
+			MajorName:  row.MajorName,
+			TuitionFee: row.TuitionFee,
+			StudyYears: row.StudyDuration,
+		})
+	}
+
+	item.Explanation = fmt.Sprintf("%s：%d年该专业组最低分%d、最低位次%d，考生位次%d，估算录取概率%.0f%%",
+		g.tier, year, item.LowestPoints, item.LowestRank, estimate.Rank, g.probability*100)
+	if note != "" {
+		item.Explanation += "；" + note
+	}
+	return item
+}
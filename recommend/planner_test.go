@@ -0,0 +1,76 @@
+package recommend
+
+import (
+	"testing"
+
+	"gaokao-zhiyuan/models"
+)
+
+func planRow(school, group, major string, rank uint32, requireChemistry bool) models.AdmissionHubeiWide {
+	return models.AdmissionHubeiWide{
+		SchoolCode:       school,
+		MajorGroupCode:   group,
+		MajorCode:        major,
+		MinScore2024:     600,
+		MinRank2024:      rank,
+		RequireChemistry: requireChemistry,
+	}
+}
+
+func TestBuildPlanEligibleByWholeGroup(t *testing.T) {
+	bands, err := ParseBands("0.15,0.5,0.85")
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimate := &Estimate{Rank: 1000, Density: 20, Sigma: 100, bands: bands}
+	// 考生未选化学
+	eligible := func(row *models.AdmissionHubeiWide) bool { return !row.RequireChemistry }
+
+	// 专业筛选后剩下的专业都不要求化学
+	rows := []models.AdmissionHubeiWide{
+		planRow("10001", "01", "080901", 1000, false),
+		planRow("10002", "01", "050201", 1000, false),
+	}
+	// 10001的01组中被筛选掉的专业要求化学
+	members := []models.AdmissionHubeiWide{
+		planRow("10001", "01", "080901", 1000, false),
+		planRow("10001", "01", "070301", 1000, true),
+		planRow("10002", "01", "050201", 1000, false),
+	}
+
+	tests := []struct {
+		name       string
+		members    []models.AdmissionHubeiWide
+		schools    []string
+		ineligible int
+	}{
+		{"只按筛选后的专业判断", nil, []string{"10001", "10002"}, 0},
+		{"按专业组全部专业判断", members, []string{"10002"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := BuildPlan(rows, estimate, estimate, MethodScore, PlanOptions{
+				Year:        models.ReferenceAdmissionYear,
+				Slots:       10,
+				Ratio:       TierRatio{3, 4, 3},
+				Eligible:    eligible,
+				GroupMajors: tt.members,
+			})
+			if plan.Excluded.Ineligible != tt.ineligible {
+				t.Errorf("Excluded.Ineligible = %d, want %d", plan.Excluded.Ineligible, tt.ineligible)
+			}
+			var schools []string
+			for _, item := range plan.Items {
+				schools = append(schools, item.CollegeCode)
+			}
+			if len(schools) != len(tt.schools) {
+				t.Fatalf("schools = %v, want %v", schools, tt.schools)
+			}
+			for i := range schools {
+				if schools[i] != tt.schools[i] {
+					t.Errorf("schools = %v, want %v", schools, tt.schools)
+				}
+			}
+		})
+	}
+}
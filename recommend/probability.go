@@ -0,0 +1,154 @@
+// Package recommend 录取概率模型与冲稳保分档
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/controlline"
+	"gaokao-zhiyuan/scorerank"
+)
+
+// Tier 冲稳保分档
+type Tier string
+
+const (
+	TierChong Tier = "冲"
+	TierWen   Tier = "稳"
+	TierBao   Tier = "保"
+)
+
+// 计算考生密度时取位次所在分数上下几分
+const densityWindow = 5
+
+// 录取线波动的下限占考生位次的比例，高分段每分人数很少时避免波动趋近于0
+const minSigmaRatio = 0.02
+
+// Bands 冲稳保分档的录取概率下限：冲 [Chong, Wen)，稳 [Wen, Bao)，保 [Bao, 1]，
+// 低于 Chong 的志愿不推荐
+type Bands struct {
+	Chong float64
+	Wen   float64
+	Bao   float64
+}
+
+// ParseBands 解析 "冲,稳,保" 三个概率下限，如 "0.15,0.5,0.85"
+func ParseBands(value string) (Bands, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return Bands{}, fmt.Errorf("冲稳保分档格式错误: %s（应为 冲,稳,保 三个概率下限）", value)
+	}
+	var v [3]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Bands{}, fmt.Errorf("冲稳保分档格式错误: %s", value)
+		}
+		v[i] = f
+	}
+	b := Bands{Chong: v[0], Wen: v[1], Bao: v[2]}
+	if !(0 < b.Chong && b.Chong < b.Wen && b.Wen < b.Bao && b.Bao < 1) {
+		return Bands{}, fmt.Errorf("冲稳保分档应满足 0 < 冲 < 稳 < 保 < 1: %s", value)
+	}
+	return b, nil
+}
+
+// 策略对应的录取概率区间，strategy 为 0冲 1稳 2保，其他值为冲稳保混合
+func (b Bands) strategyRange(strategy int) (low, high float64) {
+	switch strategy {
+	case 0:
+		return b.Chong, b.Wen
+	case 1:
+		return b.Wen, b.Bao
+	case 2:
+		return b.Bao, 1
+	default:
+		return b.Chong, 1
+	}
+}
+
+// Tier 录取概率所在的分档，低于冲的下限时返回false
+func (b Bands) Tier(p float64) (Tier, bool) {
+	switch {
+	case p >= b.Bao:
+		return TierBao, true
+	case p >= b.Wen:
+		return TierWen, true
+	case p >= b.Chong:
+		return TierChong, true
+	default:
+		return "", false
+	}
+}
+
+// Model 录取概率模型。
+// 专业组录取位次与考生位次之差按考生所在分数段的密度（一分一段表每分人数）归一化：
+// 录取位次的年际波动视为正态分布，标准差为 SigmaPoints 分对应的人数，
+// 录取概率 = Φ((录取位次 - 考生位次) / 标准差)。
+// 同样的分差在高分段对应的位次差小、在低分段对应的位次差大，按位次计算后不同分数段的概率可比
+type Model struct {
+	ranks *scorerank.Registry
+	lines *controlline.Set
+	Options
+}
+
+// Options 模型参数及各划分方法的默认窗口
+type Options struct {
+	Bands           Bands
+	SigmaPoints     float64         // 录取线年际波动的标准差（分）
+	RankWindows     RankWindows     // 位次法的默认窗口
+	LineDiffWindows LineDiffWindows // 线差法的默认窗口
+	TierRatio       TierRatio       // 志愿表冲稳保各档志愿数的默认比例
+}
+
+func NewModel(ranks *scorerank.Registry, lines *controlline.Set, opts Options) *Model {
+	return &Model{ranks: ranks, lines: lines, Options: opts}
+}
+
+// Estimate 某考生的录取概率估算，与参考年份的录取位次比较
+type Estimate struct {
+	Rank    int     `json:"rank"`    // 考生位次
+	Density float64 `json:"density"` // 考生所在分数段平均每分人数
+	Sigma   float64 `json:"sigma"`   // 录取位次波动的标准差（人）
+	bands   Bands
+}
+
+// Estimate 按参考年份一分一段表计算考生所在分数段的密度
+func (m *Model) Estimate(province string, year int, category string, rank int) (*Estimate, error) {
+	density, err := m.ranks.Density(province, year, category, rank, densityWindow)
+	if err != nil {
+		return nil, err
+	}
+	sigma := math.Max(m.SigmaPoints*density, minSigmaRatio*float64(rank))
+	return &Estimate{Rank: rank, Density: density, Sigma: math.Max(sigma, 1), bands: m.Bands}, nil
+}
+
+// Probability 录取位次为 cutoffRank 的专业组的录取概率
+func (e *Estimate) Probability(cutoffRank int64) float64 {
+	z := (float64(cutoffRank) - float64(e.Rank)) / e.Sigma
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// 录取概率为p时的录取位次
+func (e *Estimate) rankAt(p float64) float64 {
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return float64(e.Rank) + e.Sigma*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+// RankRange 策略对应的录取位次范围（闭区间），录取概率随录取位次单调递增
+func (e *Estimate) RankRange(strategy int) (low, high int64) {
+	pLow, pHigh := e.bands.strategyRange(strategy)
+	low = int64(math.Ceil(e.rankAt(pLow)))
+	if low < 1 {
+		low = 1 // 排除缺少录取位次的数据
+	}
+	high = math.MaxUint32
+	if r := e.rankAt(pHigh); !math.IsInf(r, 1) {
+		high = int64(math.Ceil(r)) - 1
+	}
+	return low, high
+}
@@ -0,0 +1,143 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"gaokao-zhiyuan/database"
+	"gaokao-zhiyuan/models"
+	"gaokao-zhiyuan/taxonomy"
+)
+
+// 调剂风险等级的下限
+const (
+	reassignmentMediumRisk = 0.2
+	reassignmentHighRisk   = 0.5
+)
+
+// 匹配不愿就读的专业：dislikes 可以是兴趣方向（理科、医科等，按该方向的 is_* 标签字段匹配）或专业名称关键词
+func matchDislike(tax *taxonomy.Taxonomy, row *models.AdmissionHubeiWide, dislikes []string) (string, bool) {
+	for _, dislike := range dislikes {
+		if interest, ok := tax.Interest(dislike); ok {
+			if taxonomy.FlagValue(row, interest.Flag) {
+				return dislike, true
+			}
+			continue
+		}
+		if dislike != "" && strings.Contains(row.MajorName, dislike) {
+			return dislike, true
+		}
+	}
+	return "", false
+}
+
+// AnalyzeReassignment 分析某个院校专业组的服从调剂风险，rows 为专业组内的全部专业，
+// studentScore 为考生分数换算到2024年的等位分。
+// 专业最低分不高于考生等位分的专业都可能被调剂进入（没有专业最低分的专业也算在内）；
+// 所有专业最低分都高于考生时，考生只能被调剂到组内分数最低的专业。
+// 调剂风险为这些专业中不愿就读专业的计划数占比，专业组没有计划数时按专业个数计算
+func AnalyzeReassignment(tax *taxonomy.Taxonomy, rows []models.AdmissionHubeiWide, studentScore int, dislikes []string) *models.Reassignment {
+	result := &models.Reassignment{StudentScore: studentScore, Majors: []models.ReassignmentMajor{}}
+	if len(rows) == 0 {
+		result.Level = reassignmentLevel(0)
+		return result
+	}
+
+	hasPlan := false
+	lowest := -1
+	for i := range rows {
+		row := &rows[i]
+		if database.EnrollmentPlan(row) > 0 {
+			hasPlan = true
+		}
+		if row.MajorMinScore2024 > 0 && (lowest < 0 || int(row.MajorMinScore2024) < lowest) {
+			lowest = int(row.MajorMinScore2024)
+		}
+		major := models.ReassignmentMajor{
+			MajorCode:         row.MajorCode,
+			MajorName:         row.MajorName,
+			MajorMinScore2024: row.MajorMinScore2024,
+			EnrollmentPlan:    database.EnrollmentPlan(row),
+			BelowStudent:      row.MajorMinScore2024 == 0 || int(row.MajorMinScore2024) <= studentScore,
+		}
+		major.DislikeMatch, major.Disliked = matchDislike(tax, row, dislikes)
+		result.Majors = append(result.Majors, major)
+	}
+	sort.SliceStable(result.Majors, func(i, j int) bool {
+		return result.Majors[i].MajorMinScore2024 < result.Majors[j].MajorMinScore2024
+	})
+
+	reachable := func(m *models.ReassignmentMajor) bool {
+		if m.BelowStudent {
+			return true
+		}
+		return !anyBelow(result.Majors) && int(m.MajorMinScore2024) == lowest
+	}
+	var total, disliked float64
+	for i := range result.Majors {
+		m := &result.Majors[i]
+		if !reachable(m) {
+			continue
+		}
+		weight := 1.0
+		if hasPlan {
+			weight = float64(m.EnrollmentPlan)
+		}
+		total += weight
+		if m.Disliked {
+			disliked += weight
+		}
+	}
+	if total > 0 {
+		result.Exposure = math.Round(disliked/total*1000) / 1000
+	}
+	result.Level = reassignmentLevel(result.Exposure)
+	return result
+}
+
+func anyBelow(majors []models.ReassignmentMajor) bool {
+	for _, m := range majors {
+		if m.BelowStudent {
+			return true
+		}
+	}
+	return false
+}
+
+func reassignmentLevel(exposure float64) string {
+	switch {
+	case exposure >= reassignmentHighRisk:
+		return "高"
+	case exposure >= reassignmentMediumRisk:
+		return "中"
+	default:
+		return "低"
+	}
+}
+
+// AttachReassignment 为院校专业组报表行附加服从调剂风险，rows 为这些专业组内的全部专业
+func AttachReassignment(tax *taxonomy.Taxonomy, groups []models.GroupItem, rows []models.AdmissionHubeiWide, studentScore int, dislikes []string) {
+	byGroup := make(map[string][]models.AdmissionHubeiWide)
+	for _, row := range rows {
+		key := row.SchoolCode + "|" + row.MajorGroupCode
+		byGroup[key] = append(byGroup[key], row)
+	}
+
+	for i := range groups {
+		group := &groups[i]
+		analysis := AnalyzeReassignment(tax, byGroup[group.CollegeCode+"|"+group.MajorGroupCode], studentScore, dislikes)
+		group.BelowStudentMajors, group.DislikedMajors = nil, nil
+		for _, m := range analysis.Majors {
+			if m.BelowStudent {
+				group.BelowStudentMajors = append(group.BelowStudentMajors, m.MajorCode)
+			}
+			if m.Disliked {
+				group.DislikedMajors = append(group.DislikedMajors, m.MajorCode)
+			}
+		}
+		exposure := analysis.Exposure
+		group.ReassignmentExposure = &exposure
+		group.ReassignmentRisk = analysis.Level
+	}
+}
@@ -0,0 +1,195 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+)
+
+// Method 冲稳保的划分方法
+type Method string
+
+const (
+	MethodScore    Method = "score"     // 按分数段密度估算录取概率（默认）
+	MethodRank     Method = "rank"      // 位次法：录取位次相对考生位次的固定窗口
+	MethodLineDiff Method = "line_diff" // 线差法：比较考生与专业组的线差
+)
+
+// ParseMethod 解析划分方法，为空时使用 score
+func ParseMethod(value string) (Method, error) {
+	switch Method(value) {
+	case "":
+		return MethodScore, nil
+	case MethodScore, MethodRank, MethodLineDiff:
+		return Method(value), nil
+	default:
+		return "", fmt.Errorf("不支持的method: %s（可选 rank、score、line_diff）", value)
+	}
+}
+
+// Window 某考生的冲稳保划分
+type Window interface {
+	// 将策略对应的录取位次或分数范围写入查询条件，strategy 为 0冲 1稳 2保，其他值为冲稳保混合
+	Apply(q *models.ReportQuery, strategy int)
+	// 报表行所在的分档，不在任何分档时返回false
+	Tier(item *models.List) (Tier, bool)
+}
+
+// Apply 按录取概率区间筛选录取位次
+func (e *Estimate) Apply(q *models.ReportQuery, strategy int) {
+	q.MinCutoffRank, q.MaxCutoffRank = e.RankRange(strategy)
+}
+
+// Tier 报表行的录取概率所在的分档
+func (e *Estimate) Tier(item *models.List) (Tier, bool) {
+	if item.LowestRank == nil || *item.LowestRank <= 0 {
+		return "", false
+	}
+	return e.bands.Tier(e.Probability(*item.LowestRank))
+}
+
+// 解析 "冲下限,稳下限,保下限,保上限" 四个递增的边界，allowPercent 时边界可以带%
+func parseBounds(value string, allowPercent bool) (bounds [4]float64, percent bool, err error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return bounds, false, fmt.Errorf("窗口格式错误: %s（应为 冲下限,稳下限,保下限,保上限）", value)
+	}
+	percentCount := 0
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if allowPercent && strings.HasSuffix(part, "%") {
+			percentCount++
+			part = strings.TrimSuffix(part, "%")
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return bounds, false, fmt.Errorf("窗口格式错误: %s", value)
+		}
+		bounds[i] = v
+	}
+	if percentCount != 0 && percentCount != len(parts) {
+		return bounds, false, fmt.Errorf("窗口不能混用百分比和绝对值: %s", value)
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			return bounds, false, fmt.Errorf("窗口边界应递增: %s", value)
+		}
+	}
+	return bounds, percentCount > 0, nil
+}
+
+// 按边界划分分档：冲 [b0, b1)，稳 [b1, b2)，保 [b2, b3]
+func boundsTier(bounds [4]int64, v int64) (Tier, bool) {
+	switch {
+	case v < bounds[0] || v > bounds[3]:
+		return "", false
+	case v >= bounds[2]:
+		return TierBao, true
+	case v >= bounds[1]:
+		return TierWen, true
+	default:
+		return TierChong, true
+	}
+}
+
+// 策略对应的边界区间（闭区间）
+func boundsRange(bounds [4]int64, strategy int) (low, high int64) {
+	switch strategy {
+	case 0:
+		return bounds[0], bounds[1] - 1
+	case 1:
+		return bounds[1], bounds[2] - 1
+	case 2:
+		return bounds[2], bounds[3]
+	default:
+		return bounds[0], bounds[3]
+	}
+}
+
+// RankWindows 位次法窗口：冲下限、稳下限、保下限、保上限四个边界，表示专业组录取位次相对考生位次的偏移。
+// 冲为 [冲下限, 稳下限)，稳为 [稳下限, 保下限)，保为 [保下限, 保上限]
+type RankWindows struct {
+	Bounds  [4]float64
+	Percent bool // 偏移为考生位次的百分比，否则为绝对位次
+}
+
+// ParseRankWindows 解析 "冲下限,稳下限,保下限,保上限"，带%时为百分比，如 "-20%,0%,15%,50%" 或 "-3000,0,2000,8000"
+func ParseRankWindows(value string) (RankWindows, error) {
+	bounds, percent, err := parseBounds(value, true)
+	if err != nil {
+		return RankWindows{}, err
+	}
+	return RankWindows{Bounds: bounds, Percent: percent}, nil
+}
+
+// For 换算为某考生位次下的绝对位次窗口
+func (w RankWindows) For(rank int) *RankWindow {
+	window := &RankWindow{Rank: rank}
+	for i, b := range w.Bounds {
+		offset := b
+		if w.Percent {
+			offset = float64(rank) * b / 100
+		}
+		window.Bounds[i] = int64(rank) + int64(math.Round(offset))
+	}
+	return window
+}
+
+// RankWindow 某考生的位次法窗口
+type RankWindow struct {
+	Rank   int
+	Bounds [4]int64
+}
+
+// Apply 按窗口筛选录取位次
+func (w *RankWindow) Apply(q *models.ReportQuery, strategy int) {
+	low, high := boundsRange(w.Bounds, strategy)
+	if low < 1 {
+		low = 1 // 排除缺少录取位次的数据
+	}
+	q.MinCutoffRank, q.MaxCutoffRank = low, high
+}
+
+// Tier 录取位次所在的窗口
+func (w *RankWindow) Tier(item *models.List) (Tier, bool) {
+	if item.LowestRank == nil || *item.LowestRank <= 0 {
+		return "", false
+	}
+	return boundsTier(w.Bounds, *item.LowestRank)
+}
+
+// Annotate 为报表行附加录取概率、冲稳保分档和划分方法
+func Annotate(list []models.List, estimate *Estimate, window Window, method Method) {
+	for i := range list {
+		item := &list[i]
+		item.Method = string(method)
+		item.AdmissionProbability = probability(estimate, item.LowestRank)
+		if tier, ok := window.Tier(item); ok {
+			item.Tier = string(tier)
+		}
+	}
+}
+
+// AnnotateGroups 为院校专业组报表行附加录取概率、冲稳保分档和划分方法
+func AnnotateGroups(groups []models.GroupItem, estimate *Estimate, window Window, method Method) {
+	for i := range groups {
+		group := &groups[i]
+		group.Method = string(method)
+		group.AdmissionProbability = probability(estimate, group.LowestRank)
+		if tier, ok := window.Tier(&models.List{LowestPoints: group.LowestPoints, LowestRank: group.LowestRank}); ok {
+			group.Tier = string(tier)
+		}
+	}
+}
+
+// 录取位次对应的录取概率，保留三位小数，缺少录取位次时为nil
+func probability(estimate *Estimate, cutoffRank *int64) *float64 {
+	if cutoffRank == nil || *cutoffRank <= 0 {
+		return nil
+	}
+	p := math.Round(estimate.Probability(*cutoffRank)*1000) / 1000
+	return &p
+}
@@ -0,0 +1,31 @@
+package scorerank
+
+// Equivalence 等位分换算结果：分数在原年份的位次，对应到目标年份的分数
+type Equivalence struct {
+	Year                int `json:"year"`                  // 原年份
+	Score               int `json:"score"`                 // 原年份分数
+	Rank                int `json:"rank"`                  // 原年份累计位次
+	TargetYear          int `json:"target_year"`           // 目标年份
+	EquivalentScore     int `json:"equivalent_score"`      // 目标年份等位分
+	EquivalentScoreHigh int `json:"equivalent_score_high"` // 目标年份等位分所在分数段的最高分
+}
+
+// EquivalentScore 通过两个年份的一分一段表，将 fromYear 的分数换算为 toYear 的等位分
+func (r *Registry) EquivalentScore(province, category string, score, fromYear, toYear int) (Equivalence, error) {
+	pos, err := r.Lookup(province, fromYear, category, score)
+	if err != nil {
+		return Equivalence{}, err
+	}
+	band, err := r.ScoreByRank(province, toYear, category, pos.Rank)
+	if err != nil {
+		return Equivalence{}, err
+	}
+	return Equivalence{
+		Year:                fromYear,
+		Score:               score,
+		Rank:                pos.Rank,
+		TargetYear:          toYear,
+		EquivalentScore:     band.ScoreLow,
+		EquivalentScoreHigh: band.ScoreHigh,
+	}, nil
+}
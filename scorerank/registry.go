@@ -0,0 +1,194 @@
+// Package scorerank 管理各省份、年份、科类的一分一段表
+package scorerank
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gaokao-zhiyuan/config"
+	"gaokao-zhiyuan/models"
+)
+
+// 科类在数据文件名中的代码
+var categoryFileCodes = map[string]string{
+	"物理": "physics",
+	"历史": "history",
+	"综合": "general",
+}
+
+// 一分一段表文件名：ranking_score_{省份代码}_{科类代码}[_{年份}].json，
+// 省略年份时视为宽表对应的参考年份
+var fileNamePattern = regexp.MustCompile(`^ranking_score_([a-z]+)_([a-z]+)(?:_(\d{4}))?\.json$`)
+
+// Registry 一分一段表注册表，按 省份/年份/科类 索引
+type Registry struct {
+	tables map[string]*Table
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{tables: make(map[string]*Table)}
+}
+
+func tableKey(province string, year int, category string) string {
+	return fmt.Sprintf("%s|%d|%s", province, year, category)
+}
+
+// Load 从文件系统中发现并加载所有一分一段表（包括子目录），
+// 文件名无法识别的省份/科类、解析失败或重复的表都会返回错误
+func Load(fsys fs.FS) (*Registry, error) {
+	r := NewRegistry()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		m := fileNamePattern.FindStringSubmatch(path.Base(p))
+		if m == nil {
+			return nil
+		}
+
+		table, err := loadTable(fsys, p, m[1], m[2], m[3])
+		if err != nil {
+			return fmt.Errorf("加载一分一段表 %s 失败: %v", p, err)
+		}
+		if err := r.Add(table); err != nil {
+			return fmt.Errorf("加载一分一段表 %s 失败: %v", p, err)
+		}
+		log.Printf("已加载%d年%s一分一段表数据：%s类 %d 条", table.Year, table.Province, table.Category, len(table.Segments))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func loadTable(fsys fs.FS, p, provinceCode, categoryCode, yearStr string) (*Table, error) {
+	profile, ok := config.GetProvinceProfileByCode(provinceCode)
+	if !ok {
+		return nil, fmt.Errorf("未知的省份代码: %s", provinceCode)
+	}
+
+	category := ""
+	for name, code := range categoryFileCodes {
+		if code == categoryCode && profile.HasCategory(name) {
+			category = name
+		}
+	}
+	if category == "" {
+		return nil, fmt.Errorf("%s不支持的科类代码: %s", profile.Name, categoryCode)
+	}
+
+	year := models.ReferenceAdmissionYear
+	if yearStr != "" {
+		year, _ = strconv.Atoi(yearStr)
+	}
+
+	file, err := fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	segments, err := parseSegments(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Table{Province: profile.Name, Year: year, Category: category, Segments: segments}, nil
+}
+
+// Add 注册一张一分一段表，同一 省份/年份/科类 只能注册一次
+func (r *Registry) Add(t *Table) error {
+	if len(t.Segments) == 0 {
+		return fmt.Errorf("%d年%s%s类一分一段表为空", t.Year, t.Province, t.Category)
+	}
+	key := tableKey(t.Province, t.Year, t.Category)
+	if _, exists := r.tables[key]; exists {
+		return fmt.Errorf("%d年%s%s类一分一段表重复", t.Year, t.Province, t.Category)
+	}
+	r.tables[key] = t
+	return nil
+}
+
+// Table 获取指定省份、年份、科类的一分一段表
+func (r *Registry) Table(province string, year int, category string) (*Table, bool) {
+	t, ok := r.tables[tableKey(province, year, category)]
+	return t, ok
+}
+
+// Tables 返回所有已注册的表，按省份、年份（降序）、科类排序
+func (r *Registry) Tables() []*Table {
+	tables := make([]*Table, 0, len(r.tables))
+	for _, t := range r.tables {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Province != tables[j].Province {
+			return tables[i].Province < tables[j].Province
+		}
+		if tables[i].Year != tables[j].Year {
+			return tables[i].Year > tables[j].Year
+		}
+		return tables[i].Category < tables[j].Category
+	})
+	return tables
+}
+
+// Require 检查省份在指定年份的所有科类都有一分一段表
+func (r *Registry) Require(province string, year int) error {
+	profile, ok := config.GetProvinceProfile(province)
+	if !ok {
+		return fmt.Errorf("不支持的省份: %s", province)
+	}
+	for _, category := range profile.SubjectCategories {
+		if _, ok := r.Table(profile.Name, year, category); !ok {
+			return fmt.Errorf("缺少%d年%s%s类一分一段表（文件名 ranking_score_%s_%s_%d.json）",
+				year, profile.Name, category, profile.Code, categoryFileCodes[category], year)
+		}
+	}
+	return nil
+}
+
+// RankByScore 根据分数查询排名
+func (r *Registry) RankByScore(province string, year int, category string, score int) (int, error) {
+	t, ok := r.Table(province, year, category)
+	if !ok {
+		return 0, fmt.Errorf("没有%d年%s%s类一分一段表", year, province, category)
+	}
+	return t.RankByScore(score), nil
+}
+
+// Lookup 查询分数在一分一段表中的位置
+func (r *Registry) Lookup(province string, year int, category string, score int) (Position, error) {
+	t, ok := r.Table(province, year, category)
+	if !ok {
+		return Position{}, fmt.Errorf("没有%d年%s%s类一分一段表", year, province, category)
+	}
+	return t.Lookup(score)
+}
+
+// ScoreByRank 查询位次所在的分数段
+func (r *Registry) ScoreByRank(province string, year int, category string, rank int) (ScoreBand, error) {
+	t, ok := r.Table(province, year, category)
+	if !ok {
+		return ScoreBand{}, fmt.Errorf("没有%d年%s%s类一分一段表", year, province, category)
+	}
+	return t.ScoreByRank(rank)
+}
+
+// Density 位次所在分数段上下 window 分范围内平均每分的人数
+func (r *Registry) Density(province string, year int, category string, rank, window int) (float64, error) {
+	t, ok := r.Table(province, year, category)
+	if !ok {
+		return 0, fmt.Errorf("没有%d年%s%s类一分一段表", year, province, category)
+	}
+	return t.Density(rank, window)
+}
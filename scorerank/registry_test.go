@@ -0,0 +1,119 @@
+package scorerank
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gaokao-zhiyuan/models"
+)
+
+const testDoc = `{"data":[{"score":"600-750","num":12,"accumulate":12},{"score":"599","num":30,"accumulate":42}]}`
+
+func TestLoadFileNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ranking_score_hubei_physics.json":           {Data: []byte(testDoc)},
+		"2023/ranking_score_hubei_history_2023.json": {Data: []byte(testDoc)},
+		"ranking_score_shandong_general_2025.json":   {Data: []byte(testDoc)},
+		// 文件名不符合格式的忽略
+		"ranking_score_hubei_physics_24.json": {Data: []byte(`不是JSON`)},
+		"ranking_score_hubei.json":            {Data: []byte(`不是JSON`)},
+		"control_lines.json":                  {Data: []byte(`不是JSON`)},
+	}
+	r, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, table := range r.Tables() {
+		got = append(got, tableKey(table.Province, table.Year, table.Category))
+	}
+	// 省略年份时为参考年份
+	want := []string{
+		tableKey("山东", 2025, "综合"),
+		tableKey("湖北", models.ReferenceAdmissionYear, "物理"),
+		tableKey("湖北", 2023, "历史"),
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("tables = %q, want %q", got, want)
+	}
+}
+
+func TestLoadFileNameErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		msg  string
+	}{
+		{"未知省份代码", fstest.MapFS{"ranking_score_mars_physics.json": {Data: []byte(testDoc)}}, "未知的省份代码: mars"},
+		{"省份不支持的科类", fstest.MapFS{"ranking_score_shandong_physics.json": {Data: []byte(testDoc)}}, "山东不支持的科类代码: physics"},
+		{"未知科类代码", fstest.MapFS{"ranking_score_hubei_arts.json": {Data: []byte(testDoc)}}, "湖北不支持的科类代码: arts"},
+		{"内容无效", fstest.MapFS{"ranking_score_hubei_physics_2023.json": {Data: []byte(`{"data":[]}`)}}, "一分一段表为空"},
+		{
+			"省略年份与参考年份重复",
+			fstest.MapFS{
+				"ranking_score_hubei_physics.json": {Data: []byte(testDoc)},
+				"ranking_score_hubei_physics_" + strconv.Itoa(models.ReferenceAdmissionYear) + ".json": {Data: []byte(testDoc)},
+			},
+			"一分一段表重复",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("error = %v, want %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestEquivalentScore(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Add(testTable()); err != nil {
+		t.Fatal(err)
+	}
+	// 2024年共42人，少于2023年的50人
+	target, err := parseSegments(strings.NewReader(testDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&Table{Province: "湖北", Year: 2024, Category: "物理", Segments: target}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		score   int
+		toYear  int
+		want    Equivalence
+		wantErr string
+	}{
+		{"落在区间分段", 700, 2024, Equivalence{Year: 2023, Score: 700, Rank: 10, TargetYear: 2024, EquivalentScore: 600, EquivalentScoreHigh: 750}, ""},
+		{"高于最高分段", 760, 2024, Equivalence{Year: 2023, Score: 760, Rank: 10, TargetYear: 2024, EquivalentScore: 600, EquivalentScoreHigh: 750}, ""},
+		{"分段之间无人", 693, 2024, Equivalence{Year: 2023, Score: 693, Rank: 15, TargetYear: 2024, EquivalentScore: 599, EquivalentScoreHigh: 599}, ""},
+		{"同一年份", 694, 2023, Equivalence{Year: 2023, Score: 694, Rank: 15, TargetYear: 2023, EquivalentScore: 694, EquivalentScoreHigh: 694}, ""},
+		{"低于原年份最低分", 690, 2024, Equivalence{}, "分数 690 低于一分一段表最低分 691"},
+		{"位次超出目标年份", 691, 2024, Equivalence{}, "位次 50 超出一分一段表范围（共 42 人）"},
+		{"目标年份没有一分一段表", 694, 2022, Equivalence{}, "没有2022年湖北物理类一分一段表"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.EquivalentScore("湖北", "物理", tt.score, 2023, tt.toYear)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("EquivalentScore(%d) = %+v, want %+v", tt.score, got, tt.want)
+			}
+		})
+	}
+}
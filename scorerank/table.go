@@ -0,0 +1,234 @@
+package scorerank
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segment 一分一段表中的一段，通常为一分，最高分段可能是区间（如 "695-750"）
+type Segment struct {
+	ScoreLow   int `json:"score_low"`  // 分段最低分
+	ScoreHigh  int `json:"score_high"` // 分段最高分
+	Num        int `json:"num"`        // 本段人数
+	Accumulate int `json:"accumulate"` // 累计人数
+}
+
+// Table 某省份某年某科类的一分一段表
+type Table struct {
+	Province string
+	Year     int
+	Category string
+	// 按分数降序排列
+	Segments []Segment
+}
+
+// 一分一段表JSON数据结构
+type segmentJSON struct {
+	Score      string `json:"score"`
+	Num        int    `json:"num"`
+	Accumulate int    `json:"accumulate"`
+}
+
+// 解析官方一分一段表JSON：{"data": [{"score": "695-750", "num": 44, "accumulate": 44}, ...]}
+func parseSegments(r io.Reader) ([]Segment, error) {
+	var doc struct {
+		Data []segmentJSON `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Data) == 0 {
+		return nil, fmt.Errorf("一分一段表为空")
+	}
+
+	segments := make([]Segment, 0, len(doc.Data))
+	for i, entry := range doc.Data {
+		low, high, err := parseScoreField(entry.Score)
+		if err != nil {
+			return nil, fmt.Errorf("第%d条记录: %v", i+1, err)
+		}
+		segments = append(segments, Segment{
+			ScoreLow:   low,
+			ScoreHigh:  high,
+			Num:        entry.Num,
+			Accumulate: entry.Accumulate,
+		})
+	}
+
+	// 按分数降序排列（高分在前）
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].ScoreLow > segments[j].ScoreLow
+	})
+	return segments, nil
+}
+
+// 解析分数字段，处理单个分数（"694"）和分数范围（"695-750"）
+func parseScoreField(scoreStr string) (low, high int, err error) {
+	if parts := strings.Split(scoreStr, "-"); len(parts) == 2 {
+		low, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+		high, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err1 != nil || err2 != nil || low > high {
+			return 0, 0, fmt.Errorf("无效的分数区间: %q", scoreStr)
+		}
+		return low, high, nil
+	}
+
+	score, err := strconv.Atoi(strings.TrimSpace(scoreStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的分数: %q", scoreStr)
+	}
+	return score, score, nil
+}
+
+// Position 某个分数在一分一段表中的位置
+type Position struct {
+	Score     int `json:"score"`      // 查询的分数
+	Rank      int `json:"rank"`       // 累计排名，即该分数及以上的总人数
+	Num       int `json:"num"`        // 同分人数
+	BestRank  int `json:"best_rank"`  // 同分考生的最好位次
+	WorstRank int `json:"worst_rank"` // 同分考生的最差位次
+}
+
+// Lookup 查询分数所在的分段，返回累计排名、同分人数和位次区间。
+// 分数高于最高分段时按最高分段计算，低于最低分段时返回错误
+func (t *Table) Lookup(score int) (Position, error) {
+	data := t.Segments
+	if score < data[len(data)-1].ScoreLow {
+		return Position{}, fmt.Errorf("分数 %d 低于一分一段表最低分 %d", score, data[len(data)-1].ScoreLow)
+	}
+	if score > data[0].ScoreHigh {
+		return segmentPosition(score, data[0]), nil
+	}
+
+	higher := 0 // 更高分段的累计人数
+	for _, seg := range data {
+		if score >= seg.ScoreLow && score <= seg.ScoreHigh {
+			return segmentPosition(score, seg), nil
+		}
+		if score > seg.ScoreHigh {
+			// 分数落在两个分段之间，说明该分数无人
+			return Position{Score: score, Rank: higher, BestRank: higher + 1, WorstRank: higher + 1}, nil
+		}
+		higher = seg.Accumulate
+	}
+	return Position{}, fmt.Errorf("分数 %d 未找到对应分段", score)
+}
+
+func segmentPosition(score int, seg Segment) Position {
+	return Position{
+		Score:     score,
+		Rank:      seg.Accumulate,
+		Num:       seg.Num,
+		BestRank:  seg.Accumulate - seg.Num + 1,
+		WorstRank: seg.Accumulate,
+	}
+}
+
+// ScoreBand 位次所在的分数段
+type ScoreBand struct {
+	Rank      int `json:"rank"`       // 查询的位次
+	ScoreLow  int `json:"score_low"`  // 分数段最低分
+	ScoreHigh int `json:"score_high"` // 分数段最高分，一分一段时与最低分相同
+	Num       int `json:"num"`        // 该分数段人数
+	BestRank  int `json:"best_rank"`  // 该分数段的最好位次
+	WorstRank int `json:"worst_rank"` // 该分数段的最差位次
+}
+
+// ScoreByRank 查询位次所在的分数段，位次超出一分一段表范围时返回错误
+func (t *Table) ScoreByRank(rank int) (ScoreBand, error) {
+	data := t.Segments
+	if rank < 1 {
+		return ScoreBand{}, fmt.Errorf("无效的位次: %d", rank)
+	}
+	for _, seg := range data {
+		if seg.Accumulate >= rank {
+			return ScoreBand{
+				Rank:      rank,
+				ScoreLow:  seg.ScoreLow,
+				ScoreHigh: seg.ScoreHigh,
+				Num:       seg.Num,
+				BestRank:  seg.Accumulate - seg.Num + 1,
+				WorstRank: seg.Accumulate,
+			}, nil
+		}
+	}
+	return ScoreBand{}, fmt.Errorf("位次 %d 超出一分一段表范围（共 %d 人）", rank, data[len(data)-1].Accumulate)
+}
+
+// Density 位次所在分数上下 window 分范围内平均每分的人数，反映该分数段的考生密度。
+// 区间分段（如 "695-750"）按分数均摊人数，超出一分一段表的分数不计入
+func (t *Table) Density(rank, window int) (float64, error) {
+	band, err := t.ScoreByRank(rank)
+	if err != nil {
+		return 0, err
+	}
+	data := t.Segments
+	low := maxInt(band.ScoreLow-window, data[len(data)-1].ScoreLow)
+	high := minInt(band.ScoreLow+window, data[0].ScoreHigh)
+
+	people := 0.0
+	for _, seg := range data {
+		overlap := minInt(high, seg.ScoreHigh) - maxInt(low, seg.ScoreLow) + 1
+		if overlap > 0 {
+			people += float64(seg.Num) * float64(overlap) / float64(seg.ScoreHigh-seg.ScoreLow+1)
+		}
+	}
+	return people / float64(high-low+1), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// RankByScore 根据分数查询排名（累计人数）
+func (t *Table) RankByScore(score int) int {
+	data := t.Segments
+
+	// 如果分数高于最高分，返回最高排名（最佳排名）
+	if score >= data[0].ScoreLow {
+		return data[0].Accumulate
+	}
+
+	// 如果分数低于最低分，返回最低排名（最差排名）
+	if score <= data[len(data)-1].ScoreLow {
+		return data[len(data)-1].Accumulate
+	}
+
+	// 线性插值查找对应排名
+	for i := 0; i < len(data)-1; i++ {
+		if score <= data[i].ScoreLow && score >= data[i+1].ScoreLow {
+			scoreRange := data[i].ScoreLow - data[i+1].ScoreLow
+			rankRange := data[i+1].Accumulate - data[i].Accumulate
+
+			if scoreRange == 0 {
+				return data[i].Accumulate
+			}
+
+			scoreDiff := score - data[i+1].ScoreLow
+			interpolatedRank := data[i+1].Accumulate - (rankRange * scoreDiff / scoreRange)
+
+			// 确保插值结果为正数，最好的排名是第1名
+			if interpolatedRank <= 0 {
+				return 1
+			}
+			return interpolatedRank
+		}
+	}
+
+	// 分段有序时不会到达这里
+	return data[len(data)-1].Accumulate
+}
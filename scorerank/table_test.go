@@ -0,0 +1,158 @@
+package scorerank
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// 测试用一分一段表：最高分段为区间，693分无人（分段之间有空档），共50人
+func testTable() *Table {
+	return &Table{
+		Province: "湖北",
+		Year:     2023,
+		Category: "物理",
+		Segments: []Segment{
+			{ScoreLow: 695, ScoreHigh: 750, Num: 10, Accumulate: 10},
+			{ScoreLow: 694, ScoreHigh: 694, Num: 5, Accumulate: 15},
+			{ScoreLow: 692, ScoreHigh: 692, Num: 20, Accumulate: 35},
+			{ScoreLow: 691, ScoreHigh: 691, Num: 15, Accumulate: 50},
+		},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		score   int
+		want    Position
+		wantErr string
+	}{
+		{"区间分段内", 700, Position{Score: 700, Rank: 10, Num: 10, BestRank: 1, WorstRank: 10}, ""},
+		{"高于最高分段", 760, Position{Score: 760, Rank: 10, Num: 10, BestRank: 1, WorstRank: 10}, ""},
+		{"一分一段", 694, Position{Score: 694, Rank: 15, Num: 5, BestRank: 11, WorstRank: 15}, ""},
+		{"分段之间无人", 693, Position{Score: 693, Rank: 15, BestRank: 16, WorstRank: 16}, ""},
+		{"最低分段", 691, Position{Score: 691, Rank: 50, Num: 15, BestRank: 36, WorstRank: 50}, ""},
+		{"低于最低分段", 690, Position{}, "分数 690 低于一分一段表最低分 691"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testTable().Lookup(tt.score)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Lookup(%d) = %+v, want %+v", tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreByRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		rank    int
+		want    ScoreBand
+		wantErr string
+	}{
+		{"第1名", 1, ScoreBand{Rank: 1, ScoreLow: 695, ScoreHigh: 750, Num: 10, BestRank: 1, WorstRank: 10}, ""},
+		{"区间分段末位", 10, ScoreBand{Rank: 10, ScoreLow: 695, ScoreHigh: 750, Num: 10, BestRank: 1, WorstRank: 10}, ""},
+		{"下一分段首位", 11, ScoreBand{Rank: 11, ScoreLow: 694, ScoreHigh: 694, Num: 5, BestRank: 11, WorstRank: 15}, ""},
+		{"跨过空档", 16, ScoreBand{Rank: 16, ScoreLow: 692, ScoreHigh: 692, Num: 20, BestRank: 16, WorstRank: 35}, ""},
+		{"最后一名", 50, ScoreBand{Rank: 50, ScoreLow: 691, ScoreHigh: 691, Num: 15, BestRank: 36, WorstRank: 50}, ""},
+		{"超出表范围", 51, ScoreBand{}, "位次 51 超出一分一段表范围（共 50 人）"},
+		{"无效位次", 0, ScoreBand{}, "无效的位次: 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testTable().ScoreByRank(tt.rank)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ScoreByRank(%d) = %+v, want %+v", tt.rank, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDensity(t *testing.T) {
+	tests := []struct {
+		name    string
+		rank    int
+		window  int
+		want    float64
+		wantErr bool
+	}{
+		// 区间分段按分数均摊人数：695-750 共56分10人
+		{"区间分段", 1, 1, (10.0*2/56 + 5) / 3, false},
+		{"包含空档", 11, 1, (10.0/56 + 5) / 3, false},
+		{"低于最低分的部分不计入", 50, 2, 35.0 / 3, false},
+		{"window为0", 16, 0, 20, false},
+		{"超出表范围", 51, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testTable().Density(tt.rank, tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Density(%d, %d) = %v, want %v", tt.rank, tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSegments(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    []Segment
+		wantErr string
+	}{
+		{
+			"按分数降序排列",
+			`{"data":[{"score":"694","num":5,"accumulate":15},{"score":"695-750","num":10,"accumulate":10}]}`,
+			[]Segment{{695, 750, 10, 10}, {694, 694, 5, 15}},
+			"",
+		},
+		{"空表", `{"data":[]}`, nil, "一分一段表为空"},
+		{"无效分数", `{"data":[{"score":"高分","num":1,"accumulate":1}]}`, nil, `第1条记录: 无效的分数: "高分"`},
+		{"区间上下限颠倒", `{"data":[{"score":"750-695","num":1,"accumulate":1}]}`, nil, `第1条记录: 无效的分数区间: "750-695"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSegments(strings.NewReader(tt.doc))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("segments = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("segments[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+// Package subjectreq 解析专业的选科要求（subject_requirement_raw），
+// 如 不限、物理+化学、物理或化学、首选物理再选化学，生成选科要求表达式树，
+// 并判断考生的选科组合是否满足要求
+package subjectreq
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Subjects 选考科目，按这个顺序输出
+var Subjects = []string{"物理", "化学", "生物", "政治", "历史", "地理"}
+
+// 科目名称及简称
+var aliases = map[string]string{
+	"物理": "物理", "物": "物理",
+	"化学": "化学", "化": "化学",
+	"生物": "生物", "生": "生物",
+	"政治": "政治", "思想政治": "政治", "思政": "政治", "政": "政治",
+	"历史": "历史", "历": "历史", "史": "历史",
+	"地理": "地理", "地": "地理",
+}
+
+// Op 表达式节点类型
+type Op string
+
+const (
+	OpSubject Op = "subject" // 必须选考 Subject
+	OpAll     Op = "all"     // 满足全部子条件
+	OpAny     Op = "any"     // 满足任一子条件
+)
+
+// Requirement 选科要求表达式树，nil 表示不限
+type Requirement struct {
+	Op       Op             `json:"op"`
+	Subject  string         `json:"subject,omitempty"`
+	Children []*Requirement `json:"children,omitempty"`
+}
+
+// Subject 必须选考某科目
+func Subject(name string) *Requirement {
+	return &Requirement{Op: OpSubject, Subject: name}
+}
+
+// All 满足全部子条件，忽略不限（nil）的子条件
+func All(children ...*Requirement) *Requirement {
+	return combine(OpAll, children)
+}
+
+// Any 满足任一子条件，任一子条件为不限（nil）时整体不限
+func Any(children ...*Requirement) *Requirement {
+	for _, c := range children {
+		if c == nil {
+			return nil
+		}
+	}
+	return combine(OpAny, children)
+}
+
+// 合并同类节点，只剩一个子条件时直接返回该子条件
+func combine(op Op, children []*Requirement) *Requirement {
+	var flat []*Requirement
+	for _, c := range children {
+		switch {
+		case c == nil:
+		case c.Op == op:
+			flat = append(flat, c.Children...)
+		default:
+			flat = append(flat, c)
+		}
+	}
+	switch len(flat) {
+	case 0:
+		return nil
+	case 1:
+		return flat[0]
+	}
+	return &Requirement{Op: op, Children: flat}
+}
+
+// Eligible 判断选科组合（首选科目和再选科目）是否满足选科要求
+func (r *Requirement) Eligible(combination []string) bool {
+	if r == nil {
+		return true
+	}
+	switch r.Op {
+	case OpSubject:
+		for _, s := range combination {
+			if s == r.Subject || aliases[s] == r.Subject {
+				return true
+			}
+		}
+		return false
+	case OpAll:
+		for _, c := range r.Children {
+			if !c.Eligible(combination) {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, c := range r.Children {
+			if c.Eligible(combination) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DNF 将选科要求展开为析取范式：满足任一组内的全部科目即可。
+// 组内科目按 Subjects 排序，去掉被其他组包含的多余组；不限时返回nil
+func (r *Requirement) DNF() [][]string {
+	if r == nil {
+		return nil
+	}
+	var clauses [][]string
+	switch r.Op {
+	case OpSubject:
+		clauses = [][]string{{r.Subject}}
+	case OpAll:
+		clauses = [][]string{{}}
+		for _, c := range r.Children {
+			var next [][]string
+			for _, left := range clauses {
+				for _, right := range c.DNF() {
+					next = append(next, append(append([]string{}, left...), right...))
+				}
+			}
+			clauses = next
+		}
+	default:
+		for _, c := range r.Children {
+			clauses = append(clauses, c.DNF()...)
+		}
+	}
+	return minimize(clauses)
+}
+
+// 组内去重排序，删除重复组和被包含的组（{物理} 满足时 {物理,化学} 多余）
+func minimize(clauses [][]string) [][]string {
+	sets := make([]map[string]bool, len(clauses))
+	for i, clause := range clauses {
+		sets[i] = make(map[string]bool)
+		for _, s := range clause {
+			sets[i][s] = true
+		}
+	}
+	subset := func(a, b map[string]bool) bool {
+		for s := range a {
+			if !b[s] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var result [][]string
+	seen := make(map[string]bool)
+	for i := range sets {
+		redundant := false
+		for j := range sets {
+			if i != j && subset(sets[j], sets[i]) && (len(sets[j]) < len(sets[i]) || j < i) {
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		var clause []string
+		for _, s := range Subjects {
+			if sets[i][s] {
+				clause = append(clause, s)
+			}
+		}
+		if key := strings.Join(clause, "+"); !seen[key] {
+			seen[key] = true
+			result = append(result, clause)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return len(result[i]) < len(result[j]) })
+	return result
+}
+
+// FromDNF 由析取范式还原选科要求，clauses 为空时为不限
+func FromDNF(clauses [][]string) *Requirement {
+	if len(clauses) == 0 {
+		return nil
+	}
+	options := make([]*Requirement, len(clauses))
+	for i, clause := range clauses {
+		var subjects []*Requirement
+		for _, s := range clause {
+			subjects = append(subjects, Subject(s))
+		}
+		options[i] = All(subjects...)
+	}
+	return Any(options...)
+}
+
+// Required 无论满足哪种选法都必须选考的科目
+func (r *Requirement) Required() []string {
+	clauses := r.DNF()
+	if len(clauses) == 0 {
+		return nil
+	}
+	var required []string
+	for _, s := range clauses[0] {
+		all := true
+		for _, clause := range clauses[1:] {
+			if !contains(clause, s) {
+				all = false
+				break
+			}
+		}
+		if all {
+			required = append(required, s)
+		}
+	}
+	return required
+}
+
+// String 选科要求的规范写法，如 物理+(化学/生物)
+func (r *Requirement) String() string {
+	if r == nil {
+		return "不限"
+	}
+	switch r.Op {
+	case OpSubject:
+		return r.Subject
+	case OpAll:
+		parts := make([]string, len(r.Children))
+		for i, c := range r.Children {
+			parts[i] = c.String()
+			if c.Op == OpAny {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, "+")
+	default:
+		parts := make([]string, len(r.Children))
+		for i, c := range r.Children {
+			parts[i] = c.String()
+		}
+		return strings.Join(parts, "/")
+	}
+}
+
+var (
+	// 括号内的说明，如 (2科必选)、（选1）
+	noteRe = regexp.MustCompile(`\(([^)]*)\)`)
+	// 多选一的说明
+	anyRe = regexp.MustCompile(`[2-6二三四五六两]选[1一]|任选(其一|一门|1门|一科|1科)?|其中(一|1)(门|科)|之一|其一|均可|选(一|1)(门|科)?`)
+	// 都要选的说明
+	allRe = regexp.MustCompile(`[1-6一二三四五六两]?(门|科)?(均需|均须|必须|都需|都须|都要|均|必|都)(选考|选)?`)
+	// 去掉说明后剩余的无意义文字
+	noise = strings.NewReplacer("选考科目", "", "科目", "", "选考", "", "要求", "", "门", "", "科", "", "选", "")
+)
+
+// Parse 解析原始选科要求，不限时返回nil。
+// 支持 不限/不提科目要求、物理+化学、物理和化学、物理、化学(2科必选)、物理或化学、化/生、
+// 化学、生物(2选1)、物化（简称连写）、首选物理再选化学、首选物理，再选化学或生物
+func Parse(raw string) (*Requirement, error) {
+	s := strings.NewReplacer(" ", "", "\t", "", "（", "(", "）", ")", "＋", "+", "／", "/", "，", ",", "；", ";").Replace(strings.TrimSpace(raw))
+
+	// 首选科目与再选科目要求同时满足，如 首选历史，再选不限
+	if rest, ok := strings.CutPrefix(s, "首选"); ok {
+		first, second, _ := strings.Cut(rest, "再选")
+		a, err := parseClause(strings.Trim(first, ",;、"))
+		if err != nil {
+			return nil, fmt.Errorf("无法识别的选科要求: %s", raw)
+		}
+		b, err := Parse(second)
+		if err != nil {
+			return nil, fmt.Errorf("无法识别的选科要求: %s", raw)
+		}
+		return All(a, b), nil
+	}
+
+	r, err := parseClause(strings.TrimPrefix(s, "再选"))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的选科要求: %s", raw)
+	}
+	return r, nil
+}
+
+// 解析不含首选/再选的选科要求
+func parseClause(s string) (*Requirement, error) {
+	pickOne := false
+	s = noteRe.ReplaceAllStringFunc(s, func(note string) string {
+		if anyRe.MatchString(note) {
+			pickOne = true
+		}
+		return ""
+	})
+	if anyRe.MatchString(s) {
+		pickOne = true
+		s = anyRe.ReplaceAllString(s, "")
+	}
+	s = noise.Replace(allRe.ReplaceAllString(s, ""))
+	if unrestricted(s) {
+		return nil, nil
+	}
+
+	// 或、/ 分隔的各项满足其一
+	if options := split(s, "或/"); len(options) > 1 {
+		children := make([]*Requirement, len(options))
+		for i, option := range options {
+			r, err := parseClause(option)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = r
+		}
+		return Any(children...), nil
+	}
+
+	var subjects []*Requirement
+	for _, token := range split(s, "+、和且,;") {
+		names, err := resolve(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			subjects = append(subjects, Subject(name))
+		}
+	}
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("没有选考科目")
+	}
+	if pickOne {
+		return Any(subjects...), nil
+	}
+	return All(subjects...), nil
+}
+
+// ParseCombination 解析考生的选科组合，如 物化生、物理+化学+地理、["历史","政治","地理"] 中的一项，
+// 返回按 Subjects 排序、去重后的科目
+func ParseCombination(raw string) ([]string, error) {
+	s := strings.NewReplacer(" ", "", "＋", "+", "，", ",").Replace(strings.TrimSpace(raw))
+	selected := make(map[string]bool)
+	for _, token := range split(s, "+、,/") {
+		names, err := resolve(token)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			selected[name] = true
+		}
+	}
+
+	var combination []string
+	for _, subject := range Subjects {
+		if selected[subject] {
+			combination = append(combination, subject)
+		}
+	}
+	if len(combination) == 0 {
+		return nil, fmt.Errorf("没有选考科目")
+	}
+	return combination, nil
+}
+
+// 科目名称或简称连写（如 物化生）
+func resolve(token string) ([]string, error) {
+	if name, ok := aliases[token]; ok {
+		return []string{name}, nil
+	}
+	var names []string
+	for _, r := range token {
+		name, ok := aliases[string(r)]
+		if !ok {
+			return nil, fmt.Errorf("无法识别的科目: %s", token)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func split(s, separators string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(separators, r)
+	})
+}
+
+func unrestricted(s string) bool {
+	return s == "" || strings.Contains(s, "不限") || strings.Contains(s, "不提") || strings.Contains(s, "无要求") || strings.Contains(s, "无限制")
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
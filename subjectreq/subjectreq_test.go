@@ -0,0 +1,89 @@
+package subjectreq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		dnf  [][]string
+	}{
+		{"", "不限", nil},
+		{"不限", "不限", nil},
+		{"不提科目要求", "不限", nil},
+		{"物理", "物理", [][]string{{"物理"}}},
+		{"思想政治", "政治", [][]string{{"政治"}}},
+		{"物理+化学", "物理+化学", [][]string{{"物理", "化学"}}},
+		{"化学和物理", "化学+物理", [][]string{{"物理", "化学"}}},
+		{"物理、化学(2科必选)", "物理+化学", [][]string{{"物理", "化学"}}},
+		{"物化", "物理+化学", [][]string{{"物理", "化学"}}},
+		{"物理或化学", "物理/化学", [][]string{{"物理"}, {"化学"}}},
+		{"物理或化学任选其一", "物理/化学", [][]string{{"物理"}, {"化学"}}},
+		{"化/生", "化学/生物", [][]string{{"化学"}, {"生物"}}},
+		{"化学、生物（2选1）", "化学/生物", [][]string{{"化学"}, {"生物"}}},
+		{"首选物理再选化学", "物理+化学", [][]string{{"物理", "化学"}}},
+		{"首选物理，再选化学或生物", "物理+(化学/生物)", [][]string{{"物理", "化学"}, {"物理", "生物"}}},
+		{"首选历史，再选不限", "历史", [][]string{{"历史"}}},
+		{"物理或物理+化学", "物理/物理+化学", [][]string{{"物理"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			r, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.raw, err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := r.DNF(); !reflect.DeepEqual(got, tt.dnf) {
+				t.Errorf("DNF() = %v, want %v", got, tt.dnf)
+			}
+		})
+	}
+}
+
+func TestParseUnknownSubject(t *testing.T) {
+	for _, raw := range []string{"英语", "物理+信息技术"} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) should fail", raw)
+		}
+	}
+}
+
+func TestEligible(t *testing.T) {
+	tests := []struct {
+		raw         string
+		combination []string
+		want        bool
+	}{
+		{"不限", []string{"历史", "政治", "地理"}, true},
+		{"物理+化学", []string{"物理", "化学", "生物"}, true},
+		{"物理+化学", []string{"物理", "生物", "地理"}, false},
+		{"物理或化学", []string{"历史", "化学", "地理"}, true},
+		{"物理或化学", []string{"历史", "政治", "地理"}, false},
+		{"首选物理，再选化学或生物", []string{"物理", "生物", "地理"}, true},
+		{"首选物理，再选化学或生物", []string{"历史", "化学", "生物"}, false},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.raw, err)
+		}
+		if got := r.Eligible(tt.combination); got != tt.want {
+			t.Errorf("Parse(%q).Eligible(%v) = %v, want %v", tt.raw, tt.combination, got, tt.want)
+		}
+		if got := FromDNF(r.DNF()).Eligible(tt.combination); got != tt.want {
+			t.Errorf("FromDNF(%q).Eligible(%v) = %v, want %v", tt.raw, tt.combination, got, tt.want)
+		}
+	}
+}
+
+func TestRequired(t *testing.T) {
+	r, _ := Parse("首选物理，再选化学或生物")
+	if got := r.Required(); !reflect.DeepEqual(got, []string{"物理"}) {
+		t.Errorf("Required() = %v, want [物理]", got)
+	}
+}
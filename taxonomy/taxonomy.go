@@ -0,0 +1,222 @@
+// Package taxonomy 专业分类体系：兴趣方向（对应gaokao2025的 is_* 标签字段）、
+// 学科门类和专业类（由国家本科专业代码推导）
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gaokao-zhiyuan/models"
+)
+
+// Interest 兴趣方向，报表按 Flag 字段筛选
+type Interest struct {
+	Name string `json:"name"`
+	Flag string `json:"flag"` // gaokao2025 中的 is_* 字段
+	// 导入数据没有 is_* 列时推导标签：专业代码属于这些学科门类或专业类时打标签，
+	// 专业代码无法识别时按专业名称关键词匹配
+	Disciplines []string `json:"disciplines,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
+
+// Discipline 学科门类，专业代码前2位
+type Discipline struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Category 专业类，专业代码前4位
+type Category struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Taxonomy 专业分类体系，Version 随数据文件更新
+type Taxonomy struct {
+	Version     string       `json:"version"`
+	Interests   []Interest   `json:"interests"`
+	Disciplines []Discipline `json:"disciplines"`
+	Categories  []Category   `json:"categories"`
+
+	interests   map[string]*Interest
+	disciplines map[string]*Discipline
+	categories  map[string]*Category // 按代码和名称索引
+}
+
+// gaokao2025 中可以作为兴趣方向标签的Bool字段 -> 结构体字段下标，is_new_major 不是专业分类标签
+var flagColumns = func() map[string]int {
+	columns := make(map[string]int)
+	modelType := reflect.TypeOf(models.AdmissionHubeiWide{})
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name := field.Tag.Get("ch")
+		if strings.HasPrefix(name, "is_") && name != "is_new_major" && field.Type.Kind() == reflect.Bool {
+			columns[name] = i
+		}
+	}
+	return columns
+}()
+
+// IsFlagColumn 判断字段是否为可用于兴趣方向的 is_* 标签字段
+func IsFlagColumn(column string) bool {
+	_, ok := flagColumns[column]
+	return ok
+}
+
+// FlagValue 录取数据的 is_* 标签字段取值，column 不是标签字段时返回false
+func FlagValue(row *models.AdmissionHubeiWide, column string) bool {
+	index, ok := flagColumns[column]
+	if !ok {
+		return false
+	}
+	return reflect.ValueOf(row).Elem().Field(index).Bool()
+}
+
+// SetFlag 设置录取数据的 is_* 标签字段
+func SetFlag(row *models.AdmissionHubeiWide, column string, value bool) {
+	if index, ok := flagColumns[column]; ok {
+		reflect.ValueOf(row).Elem().Field(index).SetBool(value)
+	}
+}
+
+// Parse 解析专业分类JSON，校验版本号、标签字段以及代码格式和唯一性
+func Parse(r io.Reader) (*Taxonomy, error) {
+	t := &Taxonomy{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	if t.Version == "" {
+		return nil, fmt.Errorf("缺少version")
+	}
+
+	t.disciplines = make(map[string]*Discipline)
+	for i := range t.Disciplines {
+		d := &t.Disciplines[i]
+		if len(d.Code) != 2 || !isDigits(d.Code) || d.Name == "" {
+			return nil, fmt.Errorf("学科门类格式错误: %s %s", d.Code, d.Name)
+		}
+		if _, ok := t.disciplines[d.Code]; ok {
+			return nil, fmt.Errorf("学科门类重复: %s", d.Code)
+		}
+		t.disciplines[d.Code] = d
+	}
+
+	t.categories = make(map[string]*Category)
+	for i := range t.Categories {
+		c := &t.Categories[i]
+		if len(c.Code) != 4 || !isDigits(c.Code) || c.Name == "" {
+			return nil, fmt.Errorf("专业类格式错误: %s %s", c.Code, c.Name)
+		}
+		if _, ok := t.disciplines[c.Code[:2]]; !ok {
+			return nil, fmt.Errorf("专业类 %s 的学科门类不存在", c.Code)
+		}
+		if _, ok := t.categories[c.Code]; ok {
+			return nil, fmt.Errorf("专业类重复: %s", c.Code)
+		}
+		if _, ok := t.categories[c.Name]; ok {
+			return nil, fmt.Errorf("专业类重复: %s", c.Name)
+		}
+		t.categories[c.Code] = c
+		t.categories[c.Name] = c
+	}
+
+	t.interests = make(map[string]*Interest)
+	for i := range t.Interests {
+		in := &t.Interests[i]
+		if in.Name == "" || !IsFlagColumn(in.Flag) {
+			return nil, fmt.Errorf("兴趣方向 %s 的标签字段错误: %s", in.Name, in.Flag)
+		}
+		if _, ok := t.interests[in.Name]; ok {
+			return nil, fmt.Errorf("兴趣方向重复: %s", in.Name)
+		}
+		for _, code := range in.Disciplines {
+			if _, ok := t.disciplines[code]; !ok {
+				return nil, fmt.Errorf("兴趣方向 %s 的学科门类不存在: %s", in.Name, code)
+			}
+		}
+		for _, code := range in.Categories {
+			if _, ok := t.categories[code]; !ok || len(code) != 4 {
+				return nil, fmt.Errorf("兴趣方向 %s 的专业类不存在: %s", in.Name, code)
+			}
+		}
+		t.interests[in.Name] = in
+	}
+	return t, nil
+}
+
+// Interest 按名称查找兴趣方向
+func (t *Taxonomy) Interest(name string) (*Interest, bool) {
+	in, ok := t.interests[name]
+	return in, ok
+}
+
+// Category 按代码或名称查找专业类
+func (t *Taxonomy) Category(codeOrName string) (*Category, bool) {
+	c, ok := t.categories[codeOrName]
+	return c, ok
+}
+
+// CategoryCode 专业代码前4位的专业类代码，如 080901 → 0809、080717T → 0807。
+// 专业代码不是国家本科专业代码（至少6位、前6位为数字）时返回false
+func CategoryCode(majorCode string) (string, bool) {
+	if len(majorCode) < 6 || !isDigits(majorCode[:6]) {
+		return "", false
+	}
+	return majorCode[:4], true
+}
+
+// CategoryOf 专业代码所属的专业类
+func (t *Taxonomy) CategoryOf(majorCode string) (*Category, bool) {
+	code, ok := CategoryCode(majorCode)
+	if !ok {
+		return nil, false
+	}
+	return t.Category(code)
+}
+
+// DisciplineOf 专业代码所属的学科门类
+func (t *Taxonomy) DisciplineOf(majorCode string) (*Discipline, bool) {
+	category, ok := t.CategoryOf(majorCode)
+	if !ok {
+		return nil, false
+	}
+	d, ok := t.disciplines[category.Code[:2]]
+	return d, ok
+}
+
+// MatchInterest 判断专业是否属于兴趣方向：专业代码属于分类体系中的专业类时按学科门类和专业类判断，
+// 否则（专科专业代码、代码缺失等）按专业名称关键词匹配
+func (t *Taxonomy) MatchInterest(in *Interest, majorCode, majorName string) bool {
+	if category, ok := t.CategoryOf(majorCode); ok {
+		for _, code := range in.Disciplines {
+			if category.Code[:2] == code {
+				return true
+			}
+		}
+		for _, code := range in.Categories {
+			if category.Code == code {
+				return true
+			}
+		}
+		return false
+	}
+	for _, keyword := range in.Keywords {
+		if strings.Contains(majorName, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}